@@ -1,15 +1,30 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/idsulik/helm-cel/pkg/converter"
+	"github.com/idsulik/helm-cel/pkg/docs"
+	"github.com/idsulik/helm-cel/pkg/formatter"
 	"github.com/idsulik/helm-cel/pkg/generator"
 	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/output"
+	"github.com/idsulik/helm-cel/pkg/postrender"
+	"github.com/idsulik/helm-cel/pkg/repl"
+	"github.com/idsulik/helm-cel/pkg/tester"
+	"github.com/idsulik/helm-cel/pkg/utils"
 	"github.com/idsulik/helm-cel/pkg/validator"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,6 +38,96 @@ var (
 	valuesFiles  []string
 	rulesFiles   []string
 	outputFormat string
+	failOn       string
+	profile      string
+	renderedFile string
+	verbose      bool
+	templateFile string
+	colorMode    string
+	noEmoji      bool
+	quiet        bool
+	recursive    bool
+	matrixFile   string
+
+	// Flags for test command
+	testFiles        []string
+	testRulesFiles   []string
+	testOutputFormat string
+	testProfile      string
+	testCoverage     bool
+	testCoverageFile string
+
+	// Flags for lint command
+	lintRulesFiles   []string
+	lintOutputFormat string
+
+	// Flags for eval command
+	evalValuesFiles  []string
+	evalRulesFiles   []string
+	evalOutputFormat string
+
+	// Flags for repl command
+	replValuesFiles []string
+	replRulesFiles  []string
+	replProfile     string
+
+	// Flags for explain command
+	explainValuesFiles  []string
+	explainRulesFiles   []string
+	explainOutputFormat string
+	explainProfile      string
+
+	// Flags for fmt command
+	fmtRulesFiles []string
+	fmtCheck      bool
+
+	// Flags for docs command
+	docsRulesFiles   []string
+	docsProfile      string
+	docsOutputFormat string
+	docsGroupBy      string
+	docsInject       string
+
+	// Flags for convert from-jsonschema command
+	convertSchemaFile string
+	convertOutputFile string
+	convertForce      bool
+
+	// Flags for convert to-jsonschema command
+	toSchemaRulesFiles []string
+	toSchemaProfile    string
+	toSchemaOutputFile string
+	toSchemaForce      bool
+
+	// Flags for export vap command
+	vapRulesFiles []string
+	vapProfile    string
+	vapName       string
+	vapOutputFile string
+
+	// Flags for post-render command, defaulting to environment variables since helm passes no flags to post-renderers
+	postRenderRulesFiles  []string
+	postRenderValuesFiles []string
+	postRenderProfile     string
+	postRenderFailOn      string
+)
+
+const (
+	// Values accepted by the --fail-on flag
+	failOnError   = "error"
+	failOnWarning = "warning"
+	failOnInfo    = "info"
+	failOnNone    = "none"
+
+	// Values accepted by the --color flag
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+
+	// Exit codes reported by the validate command
+	exitSuccess      = 0
+	exitFailure      = 1
+	exitWarningsOnly = 2
 )
 
 const (
@@ -31,20 +136,123 @@ const (
 Example using defaults: helm cel validate ./mychart
 Example with specific values: helm cel validate ./mychart -v values1.yaml -v values2.yaml
 Example with multiple files: helm cel validate ./mychart -v prod.yaml,staging.yaml -r rules1.cel.yaml,rules2.cel.yaml
+Example with several charts: helm cel validate ./charts/api ./charts/web
+Example validating all charts of a repository: helm cel validate . --recursive
+Example validating each environment of a matrix: helm cel validate ./mychart --matrix envs.yaml
 Example with JSON output: helm cel validate ./mychart -o json
-Example with YAML output: helm cel validate ./mychart -o yaml`
+Example with YAML output: helm cel validate ./mychart -o yaml
+Example with SARIF output for code scanning: helm cel validate ./mychart -o sarif > helm-cel.sarif
+Example with JUnit output: helm cel validate ./mychart -o junit > helm-cel.xml
+Example with GitHub Actions annotations: helm cel validate ./mychart -o github
+Example with a GitLab Code Quality report: helm cel validate ./mychart -o gitlab > gl-code-quality-report.json
+Example with an HTML report: helm cel validate ./mychart -o html > report.html
+Example with a Markdown summary: helm cel validate ./mychart -o template --template markdown
+Example with a custom template: helm cel validate ./mychart -o template --template report.tmpl
+Example listing passed rules: helm cel validate ./mychart --verbose
+Example for CI logs without colors and emoji: helm cel validate ./mychart --color never --no-emoji
+Example failing on warnings: helm cel validate ./mychart --fail-on warning
+Example with a rules profile: helm cel validate ./mychart --profile prod
+Example with rendered manifests: helm template ./mychart | helm cel validate ./mychart --rendered -`
 
 	generateShort = "Generate CEL validation rules from values.yaml"
 	generateLong  = `Generate values.cel.yaml file with validation rules based on the structure of values.yaml.
 Example: helm cel generate ./mychart
 Example with custom values file: helm cel generate ./mychart --values-file prod.values.yaml
 Example with force overwrite: helm cel generate ./mychart --force`
+
+	testShort = "Run test cases against CEL validation rules"
+	testLong  = `Run test files with fixture values and the rules expected to pass or fail against them.
+Example using defaults: helm cel test ./mychart
+Example with custom test files: helm cel test ./mychart -t 'tests/*.celtest.yaml,ci/*.celtest.yaml'
+Example with JUnit output: helm cel test ./mychart -o junit > report.xml
+Example with rule coverage: helm cel test ./mychart --coverage --coverage-file coverage.json`
+
+	lintShort = "Check CEL rules files for problems"
+	lintLong  = `Check rules files for syntax errors, undefined or unused named expressions, duplicate rules,
+constant rules, missing descriptions and invalid severities, without evaluating any values.
+Example using defaults: helm cel lint ./mychart
+Example with multiple files: helm cel lint ./mychart -r rules1.cel.yaml,rules2.cel.yaml
+Example with JSON output: helm cel lint ./mychart -o json`
+
+	evalShort = "Evaluate a CEL expression against chart values"
+	evalLong  = `Evaluate a single CEL expression against the merged values and print the result and its type.
+Named expressions from the rules files can be referenced with ${name} or ${name(args)}.
+Example: helm cel eval ./mychart 'values.service.port'
+Example with named expressions: helm cel eval ./mychart '${validPort}'
+Example with specific values: helm cel eval ./mychart 'size(values.ingress.hosts)' -v prod.yaml`
+
+	replShort = "Explore chart values interactively with CEL"
+	replLong  = `Start an interactive session that loads values and rules once and evaluates CEL expressions.
+Supports history, tab completion of values paths and commands like :load, :rules and :explain.
+Example: helm cel repl ./mychart
+Example with specific values: helm cel repl ./mychart -v values.yaml,prod.yaml`
+
+	explainShort = "Explain how a CEL rule is expanded and evaluated"
+	explainLong  = `Show a single rule, selected by ID or index, with every named expression substitution,
+the values paths it references with their current values and the result of each subexpression.
+Example: helm cel explain ./mychart replicas-ha
+Example by index: helm cel explain ./mychart 0
+Example with specific values: helm cel explain ./mychart replicas-ha -v prod.yaml`
+
+	fmtShort = "Format CEL rules files"
+	fmtLong  = `Rewrite rules files into a canonical layout: ordered rule keys, consistent quoting, sorted named
+expressions and CEL expressions printed with normalized spacing. Comments are preserved.
+Example using defaults: helm cel fmt ./mychart
+Example with multiple files: helm cel fmt ./mychart -r rules1.cel.yaml,rules2.cel.yaml
+Example checking formatting in CI: helm cel fmt ./mychart --check`
+
+	docsShort = "Generate documentation from CEL rules files"
+	docsLong  = `Render the merged rules as tables with their ID, description, severity, expression and the values
+paths they reference, grouped by rules file or by tag.
+Example using defaults: helm cel docs ./mychart > RULES.md
+Example grouped by tag: helm cel docs ./mychart --group-by tag
+Example with HTML output: helm cel docs ./mychart -o html > rules.html
+Example updating the chart README between markers: helm cel docs ./mychart --inject README.md`
+
+	convertShort = "Convert between CEL rules and other validation formats"
+
+	fromJSONSchemaShort = "Convert values.schema.json into CEL rules"
+	fromJSONSchemaLong  = `Generate a rules file equivalent to the chart's JSON Schema: required properties become has() checks,
+type, enum, const, minimum/maximum, pattern, length, items and additionalProperties become CEL rules.
+Keywords that cannot be converted are reported.
+Example: helm cel convert from-jsonschema ./mychart
+Example with custom files: helm cel convert from-jsonschema ./mychart --schema-file schema.json -o schema.cel.yaml`
+
+	toJSONSchemaShort = "Export CEL rules to values.schema.json"
+	toJSONSchemaLong  = `Generate a JSON Schema from the rules for Helm to enforce without the plugin: has() checks become required
+properties, type checks, comparisons, in lists, matches/startsWith/endsWith/contains and size() bounds become
+the matching keywords, all() over lists constrains items. Only error rules are enforced, other rules and
+conditions without a JSON Schema equivalent are kept as x-cel annotations.
+Example: helm cel convert to-jsonschema ./mychart
+Example with custom files: helm cel convert to-jsonschema ./mychart -r values.cel.yaml --profile strict -o values.schema.json --force`
+
+	postRenderShort = "Validate rendered manifests as a helm post-renderer"
+	postRenderLong  = `Read the manifests rendered by helm on stdin, validate them against the rules with a match and write them
+back unchanged to stdout. Validation failures are written to stderr and fail the helm command.
+Since helm passes no flags to post-renderers, options default to environment variables:
+  HELM_CEL_CHART         Chart path the rules and values files are relative to (defaults to the current directory)
+  HELM_CEL_RULES_FILES   Comma-separated rules files (defaults to values.cel.yaml)
+  HELM_CEL_VALUES_FILES  Comma-separated values files exposed as values (defaults to none)
+  HELM_CEL_PROFILE       Rules profile to apply
+  HELM_CEL_FAIL_ON       Lowest severity that fails the helm command (defaults to error)
+Example: HELM_CEL_CHART=./mychart helm install app ./mychart --post-renderer "$(helm env HELM_PLUGINS)/helm-cel/bin/helm-cel" --post-renderer-args post-render
+Example testing rules on a template: helm template ./mychart | helm cel post-render ./mychart > /dev/null`
+
+	exportShort = "Export CEL rules for enforcement outside of the plugin"
+
+	exportVAPShort = "Export CEL rules as ValidatingAdmissionPolicy manifests"
+	exportVAPLong  = `Generate ValidatingAdmissionPolicy and binding manifests enforcing the rules at admission time.
+Values paths are rewritten to fields of the admitted object using the admission section of the rules files,
+one policy is generated per resource and severity: errors are denied, warnings are returned as warnings
+and infos are audited. Rules referencing values without a mapping are listed as non-exportable.
+Example: helm cel export vap ./mychart > policies.yaml
+Example with custom files: helm cel export vap ./mychart -r values.cel.yaml --profile strict --name mychart -o policies.yaml`
 )
 
 var rootCmd = &cobra.Command{}
 
 var validateCmd = &cobra.Command{
-	Use:           "validate [flags] CHART",
+	Use:           "validate [flags] CHART...",
 	Short:         validateShort,
 	Long:          validateLong,
 	RunE:          runValidator,
@@ -61,9 +269,131 @@ var generateCmd = &cobra.Command{
 	SilenceUsage:  true,
 }
 
+var testCmd = &cobra.Command{
+	Use:           "test [flags] CHART",
+	Short:         testShort,
+	Long:          testLong,
+	RunE:          runTests,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var lintCmd = &cobra.Command{
+	Use:           "lint [flags] CHART",
+	Short:         lintShort,
+	Long:          lintLong,
+	RunE:          runLinter,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var evalCmd = &cobra.Command{
+	Use:           "eval [flags] CHART EXPRESSION",
+	Short:         evalShort,
+	Long:          evalLong,
+	RunE:          runEval,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var replCmd = &cobra.Command{
+	Use:           "repl [flags] CHART",
+	Short:         replShort,
+	Long:          replLong,
+	RunE:          runRepl,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var explainCmd = &cobra.Command{
+	Use:           "explain [flags] CHART RULE",
+	Short:         explainShort,
+	Long:          explainLong,
+	RunE:          runExplain,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var fmtCmd = &cobra.Command{
+	Use:           "fmt [flags] CHART",
+	Short:         fmtShort,
+	Long:          fmtLong,
+	RunE:          runFormatter,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var docsCmd = &cobra.Command{
+	Use:           "docs [flags] CHART",
+	Short:         docsShort,
+	Long:          docsLong,
+	RunE:          runDocs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: convertShort,
+}
+
+var fromJSONSchemaCmd = &cobra.Command{
+	Use:           "from-jsonschema [flags] CHART",
+	Short:         fromJSONSchemaShort,
+	Long:          fromJSONSchemaLong,
+	RunE:          runFromJSONSchema,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var toJSONSchemaCmd = &cobra.Command{
+	Use:           "to-jsonschema [flags] CHART",
+	Short:         toJSONSchemaShort,
+	Long:          toJSONSchemaLong,
+	RunE:          runToJSONSchema,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var postRenderCmd = &cobra.Command{
+	Use:           "post-render [flags] [CHART]",
+	Short:         postRenderShort,
+	Long:          postRenderLong,
+	RunE:          runPostRender,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: exportShort,
+}
+
+var exportVAPCmd = &cobra.Command{
+	Use:           "vap [flags] CHART",
+	Short:         exportVAPShort,
+	Long:          exportVAPLong,
+	RunE:          runExportVAP,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+}
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.AddCommand(fromJSONSchemaCmd)
+	convertCmd.AddCommand(toJSONSchemaCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(postRenderCmd)
+	exportCmd.AddCommand(exportVAPCmd)
 
 	validateCmd.Flags().StringSliceVarP(
 		&valuesFiles,
@@ -84,7 +414,68 @@ func init() {
 		"output",
 		"o",
 		"text",
-		"Output format: text, json, or yaml",
+		"Output format: text, json, yaml, sarif, junit, github, gitlab, html, or template",
+	)
+	validateCmd.Flags().StringVar(
+		&failOn,
+		"fail-on",
+		failOnError,
+		"Lowest severity that fails validation: error, warning, info, or none",
+	)
+	validateCmd.Flags().StringVar(
+		&profile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+	validateCmd.Flags().StringVar(
+		&renderedFile,
+		"rendered",
+		"",
+		"Rendered manifests to validate rules with a match against, e.g. helm template output (- for stdin)",
+	)
+	validateCmd.Flags().BoolVar(
+		&verbose,
+		"verbose",
+		false,
+		"List passed and skipped rules in text output",
+	)
+	validateCmd.Flags().StringVar(
+		&colorMode,
+		"color",
+		colorAuto,
+		"Color text output: auto (when writing to a terminal and NO_COLOR is not set), always, or never",
+	)
+	validateCmd.Flags().BoolVar(
+		&noEmoji,
+		"no-emoji",
+		false,
+		"Mark text output lines with plain text labels instead of emoji",
+	)
+	validateCmd.Flags().BoolVarP(
+		&quiet,
+		"quiet",
+		"q",
+		false,
+		"Only print errors in text output",
+	)
+	validateCmd.Flags().BoolVar(
+		&recursive,
+		"recursive",
+		false,
+		"Validate every chart with a Chart.yaml and a rules file under the given paths",
+	)
+	validateCmd.Flags().StringVar(
+		&matrixFile,
+		"matrix",
+		"",
+		"File mapping environment names to values files, each environment is validated and reported side by side",
+	)
+	validateCmd.Flags().StringVar(
+		&templateFile,
+		"template",
+		"",
+		"Go template file, or built-in template (markdown, csv, checkstyle), rendering the template output",
 	)
 
 	generateCmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "Force overwrite existing values.cel.yaml")
@@ -102,6 +493,271 @@ func init() {
 		"values.cel.yaml",
 		"Output file for generated rules",
 	)
+
+	testCmd.Flags().StringSliceVarP(
+		&testFiles,
+		"tests",
+		"t",
+		[]string{"tests/*.celtest.yaml"},
+		"Test file glob patterns, relative to the chart (comma-separated or multiple -t flags)",
+	)
+	testCmd.Flags().StringSliceVarP(
+		&testRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to test (comma-separated or multiple -r flags)",
+	)
+	testCmd.Flags().StringVarP(
+		&testOutputFormat,
+		"output",
+		"o",
+		"text",
+		"Output format: text or junit",
+	)
+	testCmd.Flags().StringVar(
+		&testProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+	testCmd.Flags().BoolVar(
+		&testCoverage,
+		"coverage",
+		false,
+		"Report whether each rule and ternary branch evaluated to both true and false",
+	)
+	testCmd.Flags().StringVar(
+		&testCoverageFile,
+		"coverage-file",
+		"",
+		"Write the coverage report as JSON to this file (implies --coverage)",
+	)
+
+	lintCmd.Flags().StringSliceVarP(
+		&lintRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to lint (comma-separated or multiple -r flags)",
+	)
+	lintCmd.Flags().StringVarP(
+		&lintOutputFormat,
+		"output",
+		"o",
+		"text",
+		"Output format: text, json, or yaml",
+	)
+
+	evalCmd.Flags().StringSliceVarP(
+		&evalValuesFiles,
+		"values-file",
+		"v",
+		[]string{"values.yaml"},
+		"Values files to evaluate against (comma-separated or multiple -v flags)",
+	)
+	evalCmd.Flags().StringSliceVarP(
+		&evalRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files providing named expressions (comma-separated or multiple -r flags)",
+	)
+	evalCmd.Flags().StringVarP(
+		&evalOutputFormat,
+		"output",
+		"o",
+		"text",
+		"Output format: text, json, or yaml",
+	)
+
+	replCmd.Flags().StringSliceVarP(
+		&replValuesFiles,
+		"values-file",
+		"v",
+		[]string{"values.yaml"},
+		"Values files to load (comma-separated or multiple -v flags)",
+	)
+	replCmd.Flags().StringSliceVarP(
+		&replRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to load (comma-separated or multiple -r flags)",
+	)
+	replCmd.Flags().StringVar(
+		&replProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+
+	explainCmd.Flags().StringSliceVarP(
+		&explainValuesFiles,
+		"values-file",
+		"v",
+		[]string{"values.yaml"},
+		"Values files to evaluate against (comma-separated or multiple -v flags)",
+	)
+	explainCmd.Flags().StringSliceVarP(
+		&explainRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to load (comma-separated or multiple -r flags)",
+	)
+	explainCmd.Flags().StringVarP(
+		&explainOutputFormat,
+		"output",
+		"o",
+		"text",
+		"Output format: text, json, or yaml",
+	)
+	explainCmd.Flags().StringVar(
+		&explainProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+
+	fmtCmd.Flags().StringSliceVarP(
+		&fmtRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to format (comma-separated or multiple -r flags)",
+	)
+	fmtCmd.Flags().BoolVar(
+		&fmtCheck,
+		"check",
+		false,
+		"Do not write files, list the files that are not formatted and exit with 1 if any",
+	)
+
+	docsCmd.Flags().StringSliceVarP(
+		&docsRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to document (comma-separated or multiple -r flags)",
+	)
+	docsCmd.Flags().StringVar(
+		&docsProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+	docsCmd.Flags().StringVarP(
+		&docsOutputFormat,
+		"output",
+		"o",
+		"markdown",
+		"Output format: markdown or html",
+	)
+	docsCmd.Flags().StringVar(
+		&docsGroupBy,
+		"group-by",
+		docs.GroupByFile,
+		"Group rules by: file or tag",
+	)
+	docsCmd.Flags().StringVar(
+		&docsInject,
+		"inject",
+		"",
+		"Markdown file, relative to the chart, to update between the helm-cel:rules:start and end markers",
+	)
+
+	fromJSONSchemaCmd.Flags().StringVar(
+		&convertSchemaFile,
+		"schema-file",
+		"values.schema.json",
+		"JSON Schema file to convert",
+	)
+	fromJSONSchemaCmd.Flags().StringVarP(
+		&convertOutputFile,
+		"output-file",
+		"o",
+		"values.cel.yaml",
+		"Output file for converted rules",
+	)
+	fromJSONSchemaCmd.Flags().BoolVarP(&convertForce, "force", "f", false, "Force overwrite existing output file")
+
+	toJSONSchemaCmd.Flags().StringSliceVarP(
+		&toSchemaRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to export (comma-separated or multiple -r flags)",
+	)
+	toJSONSchemaCmd.Flags().StringVar(
+		&toSchemaProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+	toJSONSchemaCmd.Flags().StringVarP(
+		&toSchemaOutputFile,
+		"output-file",
+		"o",
+		"values.schema.json",
+		"Output file for the JSON Schema, - for stdout",
+	)
+	toJSONSchemaCmd.Flags().BoolVarP(&toSchemaForce, "force", "f", false, "Force overwrite existing output file")
+
+	exportVAPCmd.Flags().StringSliceVarP(
+		&vapRulesFiles,
+		"rules-file",
+		"r",
+		[]string{"values.cel.yaml"},
+		"Rules files to export (comma-separated or multiple -r flags)",
+	)
+	exportVAPCmd.Flags().StringVar(
+		&vapProfile,
+		"profile",
+		"",
+		"Rules profile to apply (defined in the profiles section of the rules files)",
+	)
+	exportVAPCmd.Flags().StringVar(
+		&vapName,
+		"name",
+		"",
+		"Prefix of the policy names (defaults to the chart directory name)",
+	)
+	exportVAPCmd.Flags().StringVarP(
+		&vapOutputFile,
+		"output-file",
+		"o",
+		"-",
+		"Output file for the manifests, relative to the chart, - for stdout",
+	)
+
+	postRenderEnv := postrender.NewOptionsFromEnv()
+	postRenderCmd.Flags().StringSliceVarP(
+		&postRenderRulesFiles,
+		"rules-file",
+		"r",
+		postRenderEnv.RulesFiles,
+		"Rules files to validate against (comma-separated or multiple -r flags, defaults to $HELM_CEL_RULES_FILES)",
+	)
+	postRenderCmd.Flags().StringSliceVarP(
+		&postRenderValuesFiles,
+		"values-file",
+		"v",
+		postRenderEnv.ValuesFiles,
+		"Values files exposed to rules as values (comma-separated or multiple -v flags, defaults to $HELM_CEL_VALUES_FILES)",
+	)
+	postRenderCmd.Flags().StringVar(
+		&postRenderProfile,
+		"profile",
+		postRenderEnv.Profile,
+		"Rules profile to apply (defaults to $HELM_CEL_PROFILE)",
+	)
+	postRenderCmd.Flags().StringVar(
+		&postRenderFailOn,
+		"fail-on",
+		postRenderEnv.FailOn,
+		"Lowest severity that fails the helm command: error, warning, info, or none (defaults to $HELM_CEL_FAIL_ON)",
+	)
 }
 
 func main() {
@@ -111,99 +767,382 @@ func main() {
 	}
 }
 
-func runValidator(_ *cobra.Command, args []string) error {
-	if len(args) != 1 {
+func runValidator(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
 		return fmt.Errorf("chart path is required")
 	}
 
-	chartPath := args[0]
-	absPath, err := filepath.Abs(chartPath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %v", err)
+	switch failOn {
+	case failOnError, failOnWarning, failOnInfo, failOnNone:
+	default:
+		return fmt.Errorf("invalid --fail-on value '%s' (must be one of error, warning, info, none)", failOn)
 	}
 
-	v := validator.New()
-	result, err := v.ValidateChart(absPath, valuesFiles, rulesFiles)
-
-	if err != nil {
-		return err
+	switch colorMode {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return fmt.Errorf("invalid --color value '%s' (must be one of auto, always, never)", colorMode)
 	}
 
-	output := models.ValidationOutput{
-		HasErrors:   result.HasErrors(),
-		HasWarnings: len(result.Warnings) > 0,
-		Result:      result,
-	}
+	// Several charts are reported together, keyed by chart
+	multiple := recursive || len(args) > 1
 
+	var formatter output.Formatter
+	var err error
 	switch outputFormat {
-	case "json":
-		if err := outputJson(output); err != nil {
-			return err
-		}
-		if result.HasErrors() {
-			os.Exit(1)
-		} else if len(result.Warnings) > 0 {
-			os.Exit(2)
-		}
-	case "yaml":
-		if err := outputYaml(output); err != nil {
+	case "text":
+	case "template":
+		if formatter, err = output.NewTemplateFormatter(templateFile); err != nil {
 			return err
 		}
-		if result.HasErrors() {
-			os.Exit(1)
-		} else if len(result.Warnings) > 0 {
-			os.Exit(2)
-		}
 	default:
-		if result.HasErrors() {
-			return result
+		var ok bool
+		if formatter, ok = output.Lookup(outputFormat); !ok {
+			return fmt.Errorf(
+				"invalid output format '%s' (must be one of text, template, %s)",
+				outputFormat,
+				strings.Join(output.Names(), ", "),
+			)
 		}
+	}
+	if _, ok := formatter.(output.ChartsFormatter); multiple && formatter != nil && !ok {
+		return fmt.Errorf("output format '%s' does not support multiple charts", outputFormat)
+	}
 
-		if len(result.Warnings) > 0 {
-			fmt.Println(result.Error())
-			fmt.Println("-------------------------------------------------")
-			fmt.Println("⚠️✅ Values validation successful with warnings!")
-			// Exit with code 2 for warnings to distinguish from pure success
-			os.Exit(2)
-		} else {
-			fmt.Println("✅ Values validation successful!")
+	if matrixFile != "" {
+		switch {
+		case multiple:
+			return fmt.Errorf("--matrix can only be used with a single chart")
+		case renderedFile != "":
+			return fmt.Errorf("--rendered cannot be used with --matrix")
+		case cmd.Flags().Changed("values-file"):
+			return fmt.Errorf("--values-file cannot be used with --matrix, the matrix lists the values files")
+		}
+		if _, ok := formatter.(output.MatrixFormatter); formatter != nil && !ok {
+			return fmt.Errorf("output format '%s' does not support matrix validation", outputFormat)
 		}
 	}
 
-	return nil
-}
-
-func outputJson(output models.ValidationOutput) error {
-	json, err := json.MarshalIndent(output, "", "  ")
+	charts, err := resolveCharts(args)
 	if err != nil {
-		return fmt.Errorf("failed to marshal output to JSON: %v", err)
+		return err
+	}
+	if multiple && renderedFile != "" {
+		return fmt.Errorf("--rendered can only be used with a single chart")
 	}
-	fmt.Println(string(json))
-	return nil
-}
 
-func outputYaml(output models.ValidationOutput) error {
-	yaml, err := yaml.Marshal(output)
+	var reports []*output.Report
+	if matrixFile != "" {
+		reports, err = validateMatrix(charts[0])
+	} else {
+		reports, err = validateCharts(charts)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to marshal output to YAML: %v", err)
+		return err
 	}
-	fmt.Println(string(yaml))
-	return nil
-}
 
-func runGenerator(_ *cobra.Command, args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("chart path is required")
+	code := exitSuccess
+	for _, report := range reports {
+		code = worstExitCode(code, exitCode(report.Result, failOn))
 	}
 
-	chartPath := args[0]
-	absPath, err := filepath.Abs(chartPath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %v", err)
+	out := os.Stdout
+	if formatter == nil {
+		// Failing text output is written to stderr like other errors
+		if code == exitFailure {
+			out = os.Stderr
+		}
+		formatter = output.NewTextFormatter(
+			output.TextOptions{
+				Color:   colorEnabled(out),
+				Emoji:   !noEmoji,
+				Quiet:   quiet,
+				Verbose: verbose,
+				Width:   terminalWidth(out),
+			},
+		)
 	}
 
-	// Construct paths
-	valuesPath := filepath.Join(absPath, genValuesFile)
+	switch {
+	case matrixFile != "":
+		err = formatter.(output.MatrixFormatter).FormatMatrix(out, reports)
+	case multiple:
+		err = formatter.(output.ChartsFormatter).FormatCharts(out, reports)
+	default:
+		err = formatter.Format(out, reports[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	if code != exitSuccess {
+		os.Exit(code)
+	}
+
+	return nil
+}
+
+// resolveCharts returns the absolute paths of the charts to validate, discovering the charts under each path
+// with --recursive
+func resolveCharts(paths []string) ([]string, error) {
+	charts := make([]string, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		if !recursive {
+			charts = append(charts, absPath)
+			continue
+		}
+
+		found, err := utils.FindCharts(absPath, rulesFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find charts in %s: %v", path, err)
+		}
+		charts = append(charts, found...)
+	}
+
+	if len(charts) == 0 {
+		return nil, fmt.Errorf("no charts with rules files found in %s", strings.Join(paths, ", "))
+	}
+	return charts, nil
+}
+
+// validateCharts validates the charts in parallel, returning their reports in the same order
+func validateCharts(charts []string) ([]*output.Report, error) {
+	reports := make([]*output.Report, len(charts))
+	errs := make([]error, len(charts))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, chart := range charts {
+		wg.Add(1)
+		go func(i int, chart string) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			reports[i], errs[i] = validateChart(chart)
+		}(i, chart)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if len(charts) > 1 {
+			return nil, fmt.Errorf("chart %s: %v", charts[i], err)
+		}
+		return nil, err
+	}
+	return reports, nil
+}
+
+// validateChart validates the values of a chart, and the rendered manifests if given, against its rules
+func validateChart(absPath string) (*output.Report, error) {
+	v := validator.New(validator.WithProfile(profile))
+	values, err := v.LoadChartValues(absPath, valuesFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := v.LoadChartRules(absPath, rulesFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.ValidationResult
+	if renderedFile != "" {
+		manifests, err := loadRendered()
+		if err != nil {
+			return nil, err
+		}
+		result, err = v.ValidateManifests(values, manifests, rules)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		result, err = v.Validate(values, rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newReport(absPath, valuesFiles, values, rules, result)
+}
+
+// validateMatrix validates the values of each environment of the --matrix file against the rules of a chart,
+// reusing the compiled rules across environments
+func validateMatrix(absPath string) ([]*output.Report, error) {
+	environments, err := validator.NewMatrixLoader().LoadMatrix(matrixFile)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New(validator.WithProfile(profile))
+	rules, err := v.LoadChartRules(absPath, rulesFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*output.Report, 0, len(environments))
+	for _, environment := range environments {
+		values, err := v.LoadChartValues(absPath, environment.ValuesFiles)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %v", environment.Name, err)
+		}
+
+		result, err := v.Validate(values, rules)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := newReport(absPath, environment.ValuesFiles, values, rules, result)
+		if err != nil {
+			return nil, err
+		}
+		report.Environment = environment.Name
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// newReport describes the validation of values loaded from values files of a chart
+func newReport(
+	absPath string,
+	valuesFiles []string,
+	values map[string]any,
+	rules *models.ValidationRules,
+	result *models.ValidationResult,
+) (*output.Report, error) {
+	absValuesFiles, err := utils.GetAbsolutePaths(absPath, valuesFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values absolute paths: %v", err)
+	}
+
+	return &output.Report{
+		Result:      result,
+		Rules:       rules.Rules,
+		ChartPath:   absPath,
+		ValuesFiles: absValuesFiles,
+		Values:      values,
+		Failed:      exitCode(result, failOn) == exitFailure,
+	}, nil
+}
+
+// loadRendered reads the rendered manifests from the --rendered file or stdin
+func loadRendered() ([]map[string]any, error) {
+	var reader io.Reader = os.Stdin
+	if renderedFile != "-" {
+		file, err := os.Open(renderedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rendered manifests: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	manifests, err := validator.NewManifestsLoader().LoadManifests(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rendered manifests: %v", err)
+	}
+	return manifests, nil
+}
+
+// worstExitCode returns the most severe of two exit codes, failures before warnings
+func worstExitCode(a, b int) int {
+	if a == exitFailure || b == exitFailure {
+		return exitFailure
+	}
+	if a == exitWarningsOnly || b == exitWarningsOnly {
+		return exitWarningsOnly
+	}
+	return exitSuccess
+}
+
+// exitCode maps the validation result to the process exit code for the given --fail-on threshold
+func exitCode(result *models.ValidationResult, failOn string) int {
+	hasWarnings := len(result.Warnings) > 0
+	hasInfos := len(result.Infos) > 0
+
+	switch failOn {
+	case failOnNone:
+		return exitSuccess
+	case failOnInfo:
+		if result.HasErrors() || hasWarnings || hasInfos {
+			return exitFailure
+		}
+	case failOnWarning:
+		if result.HasErrors() || hasWarnings {
+			return exitFailure
+		}
+	default:
+		if result.HasErrors() {
+			return exitFailure
+		}
+		if hasWarnings {
+			// Exit with code 2 for warnings to distinguish from pure success
+			return exitWarningsOnly
+		}
+	}
+
+	return exitSuccess
+}
+
+// colorEnabled reports whether to color the text output written to the file, NO_COLOR disables auto-detection
+func colorEnabled(file *os.File) bool {
+	switch colorMode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && term.IsTerminal(int(file.Fd()))
+}
+
+// terminalWidth returns the width of the terminal the file is written to, or COLUMNS, 0 if unknown
+func terminalWidth(file *os.File) int {
+	if width, _, err := term.GetSize(int(file.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 0
+}
+
+func outputJson(output any) error {
+	json, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output to JSON: %v", err)
+	}
+	fmt.Println(string(json))
+	return nil
+}
+
+func outputYaml(output any) error {
+	yaml, err := yaml.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal output to YAML: %v", err)
+	}
+	fmt.Println(string(yaml))
+	return nil
+}
+
+func runGenerator(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	// Construct paths
+	valuesPath := filepath.Join(absPath, genValuesFile)
 	celPath := filepath.Join(absPath, outputFile)
 
 	// Check if values file exists
@@ -234,3 +1173,567 @@ func runGenerator(_ *cobra.Command, args []string) error {
 	fmt.Printf("✅ Successfully generated %s\n", celPath)
 	return nil
 }
+
+func runTests(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	switch testOutputFormat {
+	case "text", "junit":
+	default:
+		return fmt.Errorf("invalid output format '%s' (must be one of text, junit)", testOutputFormat)
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	files, err := tester.FindTestFiles(absPath, testFiles)
+	if err != nil {
+		return err
+	}
+
+	opts := []validator.Option{validator.WithProfile(testProfile)}
+	coverage := tester.NewCoverage()
+	if testCoverage || testCoverageFile != "" {
+		opts = append(opts, validator.WithEvalObserver(coverage.Observe))
+	}
+
+	v := validator.New(opts...)
+	rules, err := v.LoadChartRules(absPath, testRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	report, err := tester.New(v).Run(files, rules)
+	if err != nil {
+		return err
+	}
+
+	switch testOutputFormat {
+	case "junit":
+		err = tester.WriteJUnit(os.Stdout, report, absPath)
+	default:
+		err = tester.WriteText(os.Stdout, report, absPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	if testCoverage || testCoverageFile != "" {
+		if err := writeCoverage(coverage.Report(rules)); err != nil {
+			return err
+		}
+	}
+
+	if report.Failed() {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// writeCoverage prints the coverage summary and writes the JSON report if requested
+func writeCoverage(report *tester.CoverageReport) error {
+	// Keep stdout parseable when it carries the JUnit report
+	out := os.Stdout
+	if testOutputFormat == "junit" {
+		out = os.Stderr
+	}
+
+	_, _ = fmt.Fprintln(out)
+	if err := report.WriteText(out); err != nil {
+		return err
+	}
+
+	if testCoverageFile == "" {
+		return nil
+	}
+
+	file, err := os.Create(testCoverageFile)
+	if err != nil {
+		return fmt.Errorf("failed to create coverage file: %v", err)
+	}
+	defer file.Close()
+
+	return report.WriteJSON(file)
+}
+
+func runLinter(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	issues, err := validator.NewLinter().LintChart(absPath, lintRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	lintOutput := models.LintOutput{Issues: issues}
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == validator.ErrorSeverity {
+			errorCount++
+		}
+	}
+	lintOutput.HasErrors = errorCount > 0
+	lintOutput.HasWarnings = len(issues) > errorCount
+
+	switch lintOutputFormat {
+	case "json":
+		if err := outputJson(lintOutput); err != nil {
+			return err
+		}
+	case "yaml":
+		if err := outputYaml(lintOutput); err != nil {
+			return err
+		}
+	default:
+		for _, issue := range issues {
+			fmt.Println(issue.String())
+		}
+		if len(issues) == 0 {
+			fmt.Println("✅ No problems found in rules files!")
+		} else {
+			fmt.Println("-------------------------------------------------")
+			fmt.Printf("Found %d error(s) and %d warning(s)\n", errorCount, len(issues)-errorCount)
+		}
+	}
+
+	if lintOutput.HasErrors {
+		os.Exit(exitFailure)
+	} else if lintOutput.HasWarnings {
+		os.Exit(exitWarningsOnly)
+	}
+
+	return nil
+}
+
+func runEval(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("chart path and expression are required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	v := validator.New()
+	values, err := v.LoadChartValues(absPath, evalValuesFiles)
+	if err != nil {
+		return err
+	}
+
+	// The default rules file is optional, named expressions are simply unavailable without it
+	var rules *models.ValidationRules
+	if cmd.Flags().Changed("rules-file") || fileExists(filepath.Join(absPath, evalRulesFiles[0])) {
+		rules, err = v.LoadChartRules(absPath, evalRulesFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	evaluation, err := v.Evaluate(args[1], values, rules)
+	if err != nil {
+		return err
+	}
+
+	switch evalOutputFormat {
+	case "json":
+		return outputJson(evaluation)
+	case "yaml":
+		return outputYaml(evaluation)
+	default:
+		if evaluation.Expanded != "" {
+			fmt.Printf("Expanded: %s\n", evaluation.Expanded)
+		}
+		switch evaluation.Value.(type) {
+		case nil:
+			fmt.Println("Value: null")
+		case map[string]any, []any:
+			value, err := yaml.Marshal(evaluation.Value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal value to YAML: %v", err)
+			}
+			fmt.Printf("Value:\n%s", value)
+		default:
+			fmt.Printf("Value: %v\n", evaluation.Value)
+		}
+		fmt.Printf("Type: %s\n", evaluation.Type)
+	}
+
+	return nil
+}
+
+// fileExists reports whether the path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func runRepl(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	v := validator.New(validator.WithProfile(replProfile))
+	values, err := v.LoadChartValues(absPath, replValuesFiles)
+	if err != nil {
+		return err
+	}
+
+	// The default rules file is optional, rules and named expressions are simply unavailable without it
+	var rules *models.ValidationRules
+	if cmd.Flags().Changed("rules-file") || fileExists(filepath.Join(absPath, replRulesFiles[0])) {
+		rules, err = v.LoadChartRules(absPath, replRulesFiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	return repl.New(v, absPath, values, rules).Run(os.Stdin, os.Stdout)
+}
+
+func runExplain(_ *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("chart path and rule are required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	v := validator.New(validator.WithProfile(explainProfile))
+	values, err := v.LoadChartValues(absPath, explainValuesFiles)
+	if err != nil {
+		return err
+	}
+
+	rules, err := v.LoadChartRules(absPath, explainRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	rule, ok := validator.FindRule(rules, args[1])
+	if !ok {
+		return fmt.Errorf("rule '%s' not found", args[1])
+	}
+
+	explanation, err := v.Explain(rule, values, rules)
+	if err != nil {
+		return err
+	}
+
+	switch explainOutputFormat {
+	case "json":
+		return outputJson(explanation)
+	case "yaml":
+		return outputYaml(explanation)
+	default:
+		fmt.Print(explanation.String())
+	}
+
+	return nil
+}
+
+func runFormatter(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	f := formatter.New()
+	unformatted := 0
+	for _, file := range fmtRulesFiles {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(absPath, file)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rules file %s: %v", file, err)
+		}
+
+		formatted, err := f.Format(content)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %v", file, err)
+		}
+		if bytes.Equal(content, formatted) {
+			continue
+		}
+
+		unformatted++
+		if fmtCheck {
+			fmt.Printf("%s is not formatted\n", file)
+			continue
+		}
+		if err := os.WriteFile(path, formatted, 0644); err != nil {
+			return fmt.Errorf("failed to write rules file %s: %v", file, err)
+		}
+		fmt.Printf("Formatted %s\n", file)
+	}
+
+	if fmtCheck && unformatted > 0 {
+		os.Exit(exitFailure)
+	}
+
+	return nil
+}
+
+func runDocs(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	if docsInject != "" && docsOutputFormat != "markdown" {
+		return fmt.Errorf("--inject requires markdown output")
+	}
+
+	v := validator.New(validator.WithProfile(docsProfile))
+	rules, err := v.LoadChartRules(absPath, docsRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	groups, err := docs.Build(rules, absPath, docsGroupBy)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch docsOutputFormat {
+	case "markdown":
+		err = docs.WriteMarkdown(&buf, groups)
+	case "html":
+		err = docs.WriteHTML(&buf, groups)
+	default:
+		return fmt.Errorf("invalid output format '%s' (must be one of markdown, html)", docsOutputFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render documentation: %v", err)
+	}
+
+	if docsInject == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	path := docsInject
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(absPath, path)
+	}
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", docsInject, err)
+	}
+	updated, err := docs.Inject(document, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", docsInject, err)
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", docsInject, err)
+	}
+	fmt.Printf("Updated %s\n", docsInject)
+
+	return nil
+}
+
+func runFromJSONSchema(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	schemaPath := filepath.Join(absPath, convertSchemaFile)
+	celPath := filepath.Join(absPath, convertOutputFile)
+
+	if !convertForce {
+		if _, err := os.Stat(celPath); err == nil {
+			return fmt.Errorf("output file already exists: %s (use --force to overwrite)", celPath)
+		}
+	}
+
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %v", err)
+	}
+
+	rules, issues, err := converter.FromJSONSchema(content)
+	if err != nil {
+		return err
+	}
+
+	if err := generator.New().WriteRules(celPath, rules); err != nil {
+		return fmt.Errorf("failed to write rules: %v", err)
+	}
+
+	for _, issue := range issues {
+		_, _ = fmt.Fprintf(os.Stderr, "⚠️ %s\n", issue)
+	}
+	fmt.Printf("✅ Successfully converted %d rule(s) to %s\n", len(rules.Rules), celPath)
+	if len(issues) > 0 {
+		fmt.Printf("⚠️ %d keyword(s) could not be converted, review them manually\n", len(issues))
+	}
+
+	return nil
+}
+
+func runToJSONSchema(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	schemaPath := filepath.Join(absPath, toSchemaOutputFile)
+	if toSchemaOutputFile != "-" && !toSchemaForce {
+		if _, err := os.Stat(schemaPath); err == nil {
+			return fmt.Errorf("output file already exists: %s (use --force to overwrite)", schemaPath)
+		}
+	}
+
+	v := validator.New(validator.WithProfile(toSchemaProfile))
+	rules, err := v.LoadChartRules(absPath, toSchemaRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	schema, report, err := converter.ToJSONSchema(rules)
+	if err != nil {
+		return err
+	}
+
+	// Expressions are kept readable, without escaping <, > and &
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return fmt.Errorf("failed to marshal schema to JSON: %v", err)
+	}
+
+	if toSchemaOutputFile == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	if err := os.WriteFile(schemaPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write schema file: %v", err)
+	}
+
+	fmt.Printf("✅ Successfully exported rules to %s\n", schemaPath)
+	fmt.Print(report)
+
+	return nil
+}
+
+func runExportVAP(_ *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("chart path is required")
+	}
+
+	chartPath := args[0]
+	absPath, err := filepath.Abs(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	name := vapName
+	if name == "" {
+		name = filepath.Base(absPath)
+	}
+
+	v := validator.New(validator.WithProfile(vapProfile))
+	rules, err := v.LoadChartRules(absPath, vapRulesFiles)
+	if err != nil {
+		return err
+	}
+
+	export, err := converter.ToValidatingAdmissionPolicies(rules, name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteManifests(&buf); err != nil {
+		return err
+	}
+
+	for _, rule := range export.NonExportable {
+		_, _ = fmt.Fprintf(os.Stderr, "⚠️ Non-exportable %s\n", rule)
+	}
+
+	if vapOutputFile == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	outputPath := filepath.Join(absPath, vapOutputFile)
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write manifests: %v", err)
+	}
+	fmt.Printf("✅ Successfully exported %d policy(ies) to %s\n", len(export.Policies), outputPath)
+
+	return nil
+}
+
+func runPostRender(_ *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("at most one chart path is allowed")
+	}
+
+	opts := postrender.NewOptionsFromEnv()
+	if len(args) == 1 {
+		opts.ChartPath = args[0]
+	}
+	opts.RulesFiles = postRenderRulesFiles
+	opts.ValuesFiles = postRenderValuesFiles
+	opts.Profile = postRenderProfile
+	opts.FailOn = postRenderFailOn
+
+	if code, err := postrender.Run(os.Stdin, os.Stdout, os.Stderr, opts); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(code)
+	}
+	return nil
+}
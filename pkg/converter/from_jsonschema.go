@@ -0,0 +1,595 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// maxRefDepth bounds the resolution of nested $ref, recursive schemas cannot be expressed as rules
+const maxRefDepth = 32
+
+// Issue is a JSON Schema keyword that could not be converted
+type Issue struct {
+	Path    string `json:"path" yaml:"path"` // JSON pointer of the schema containing the keyword
+	Keyword string `json:"keyword" yaml:"keyword"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+func (i Issue) String() string {
+	if i.Reason != "" {
+		return fmt.Sprintf("%s: unsupported keyword '%s': %s", i.Path, i.Keyword, i.Reason)
+	}
+	return fmt.Sprintf("%s: unsupported keyword '%s'", i.Path, i.Keyword)
+}
+
+// keywordOrder is the order in which keywords are converted, so that presence and type rules come first
+var keywordOrder = []string{
+	"required", "type", "enum", "const",
+	"minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "multipleOf",
+	"minLength", "maxLength", "pattern",
+	"minItems", "maxItems", "uniqueItems",
+	"minProperties", "maxProperties", "additionalProperties", "patternProperties",
+	"properties", "items",
+}
+
+// celKeywords cannot be used as field names in CEL field selections
+var celKeywords = map[string]bool{"in": true, "true": true, "false": true, "null": true}
+
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// annotationKeywords carry no validation and are skipped without being reported
+var annotationKeywords = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"id":          true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"readOnly":    true,
+	"writeOnly":   true,
+	"deprecated":  true,
+	"definitions": true,
+	"$defs":       true,
+}
+
+// jsonSchemaConverter converts a JSON Schema into rules, collecting the keywords it cannot convert
+type jsonSchemaConverter struct {
+	root     map[string]any
+	rules    []models.Rule
+	issues   []Issue
+	refDepth int
+}
+
+// FromJSONSchema converts a values.schema.json into equivalent CEL rules. Keywords that have no
+// equivalent rule are returned as issues rather than being dropped silently.
+func FromJSONSchema(content []byte) (*models.ValidationRules, []Issue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON schema: %v", err)
+	}
+
+	c := &jsonSchemaConverter{root: root}
+	c.convert(root, "#", "values", "values", func(cond string) string { return cond })
+
+	return &models.ValidationRules{Rules: c.rules}, c.issues, nil
+}
+
+// convert adds the rules of a schema validating subject, a CEL expression such as values.image.tag.
+// label names the subject in descriptions and wrap turns a condition on the subject into the rule expression,
+// guarding it against missing parents and iterating over enclosing lists and maps.
+func (c *jsonSchemaConverter) convert(schema map[string]any, ptr, subject, label string, wrap func(string) string) {
+	schema, ptr, ok := c.resolve(schema, ptr)
+	if !ok {
+		return
+	}
+
+	add := func(cond, format string, args ...any) {
+		c.rules = append(
+			c.rules, models.Rule{
+				Expr: wrap(cond),
+				Desc: fmt.Sprintf("%s %s", label, fmt.Sprintf(format, args...)),
+			},
+		)
+	}
+	unsupported := func(keyword, reason string) {
+		c.issues = append(c.issues, Issue{Path: ptr, Keyword: keyword, Reason: reason})
+	}
+
+	for _, keyword := range orderedKeywords(schema) {
+		value := schema[keyword]
+		switch keyword {
+		case "type":
+			if subject == "values" {
+				continue
+			}
+			c.convertType(value, subject, add, unsupported)
+		case "enum":
+			values, ok := value.([]any)
+			if !ok {
+				unsupported(keyword, "must be a list")
+				continue
+			}
+			literals, ok := celLiterals(values)
+			if !ok {
+				unsupported(keyword, "only scalar values can be converted")
+				continue
+			}
+			add(fmt.Sprintf("%s in [%s]", subject, literals), "must be one of [%s]", literals)
+		case "const":
+			literal, ok := celLiteral(value)
+			if !ok {
+				unsupported(keyword, "only scalar values can be converted")
+				continue
+			}
+			add(fmt.Sprintf("%s == %s", subject, literal), "must be %s", literal)
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			c.convertBound(schema, keyword, subject, add, unsupported)
+		case "multipleOf":
+			n, ok := integer(value)
+			if !ok {
+				unsupported(keyword, "only integers can be converted")
+				continue
+			}
+			add(fmt.Sprintf("%s %% %d == 0", subject, n), "must be a multiple of %d", n)
+		case "minLength", "minItems", "minProperties":
+			n, ok := integer(value)
+			if !ok {
+				unsupported(keyword, "must be an integer")
+				continue
+			}
+			add(fmt.Sprintf("size(%s) >= %d", subject, n), "must have at least %d %s", n, sizeUnit(keyword, n))
+		case "maxLength", "maxItems", "maxProperties":
+			n, ok := integer(value)
+			if !ok {
+				unsupported(keyword, "must be an integer")
+				continue
+			}
+			add(fmt.Sprintf("size(%s) <= %d", subject, n), "must have at most %d %s", n, sizeUnit(keyword, n))
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				unsupported(keyword, "must be a string")
+				continue
+			}
+			add(fmt.Sprintf("%s.matches(%s)", subject, strconv.Quote(pattern)), "must match %s", pattern)
+		case "uniqueItems":
+			if value != true {
+				continue
+			}
+			x, y := variable("x", subject), variable("y", subject)
+			add(
+				fmt.Sprintf("%s.all(%s, %s.filter(%s, %s == %s).size() == 1)", subject, x, subject, y, y, x),
+				"must not contain duplicate items",
+			)
+		case "required":
+			required, ok := value.([]any)
+			if !ok {
+				unsupported(keyword, "must be a list")
+				continue
+			}
+			for _, name := range required {
+				name, ok := name.(string)
+				if !ok {
+					unsupported(keyword, "must be a list of strings")
+					continue
+				}
+				c.rules = append(
+					c.rules, models.Rule{
+						Expr: wrap(presence(subject, name)),
+						Desc: fmt.Sprintf("%s is required", childLabel(label, name)),
+					},
+				)
+			}
+		case "properties":
+			properties, ok := value.(map[string]any)
+			if !ok {
+				unsupported(keyword, "must be an object")
+				continue
+			}
+			for _, name := range sortedKeys(properties) {
+				property, ok := properties[name].(map[string]any)
+				if !ok {
+					unsupported(keyword, fmt.Sprintf("property '%s' must be an object", name))
+					continue
+				}
+				child := field(subject, name)
+				guard := "!" + presence(subject, name)
+				if !strings.HasPrefix(guard, "!has(") {
+					guard = fmt.Sprintf("!(%s)", presence(subject, name))
+				}
+				c.convert(
+					property,
+					ptr+"/properties/"+escapePointer(name),
+					child,
+					childLabel(label, name),
+					func(cond string) string {
+						return wrap(fmt.Sprintf("%s || %s", guard, cond))
+					},
+				)
+			}
+		case "additionalProperties":
+			c.convertAdditionalProperties(schema, ptr, subject, label, wrap, add, unsupported)
+		case "items":
+			items, ok := value.(map[string]any)
+			if !ok {
+				unsupported(keyword, "only a single schema for all items can be converted")
+				continue
+			}
+			v := variable("item", subject)
+			c.convert(
+				items,
+				ptr+"/items",
+				v,
+				label+"[]",
+				func(cond string) string {
+					return wrap(fmt.Sprintf("%s.all(%s, %s)", subject, v, cond))
+				},
+			)
+		case "$ref", "patternProperties":
+			// $ref is resolved above, patternProperties are handled with additionalProperties
+			if keyword == "patternProperties" && schema["additionalProperties"] == nil {
+				unsupported(keyword, "only supported together with additionalProperties")
+			}
+		default:
+			if !annotationKeywords[keyword] {
+				unsupported(keyword, "")
+			}
+		}
+	}
+}
+
+// resolve replaces a local $ref with the schema it points to, keywords next to $ref are kept
+func (c *jsonSchemaConverter) resolve(schema map[string]any, ptr string) (map[string]any, string, bool) {
+	ref, ok := schema["$ref"].(string)
+	if !ok {
+		return schema, ptr, true
+	}
+	if !strings.HasPrefix(ref, "#") {
+		c.issues = append(c.issues, Issue{Path: ptr, Keyword: "$ref", Reason: "only local references can be converted"})
+		return nil, ptr, false
+	}
+	if c.refDepth >= maxRefDepth {
+		c.issues = append(c.issues, Issue{Path: ptr, Keyword: "$ref", Reason: "recursive references cannot be converted"})
+		return nil, ptr, false
+	}
+
+	var target any = c.root
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := target.(map[string]any)
+		if !ok {
+			target = nil
+			break
+		}
+		target = m[part]
+	}
+	resolved, ok := target.(map[string]any)
+	if !ok {
+		c.issues = append(c.issues, Issue{Path: ptr, Keyword: "$ref", Reason: fmt.Sprintf("'%s' not found", ref)})
+		return nil, ptr, false
+	}
+
+	merged := make(map[string]any, len(resolved)+len(schema))
+	for k, v := range resolved {
+		merged[k] = v
+	}
+	for k, v := range schema {
+		if k != "$ref" {
+			merged[k] = v
+		}
+	}
+
+	c.refDepth++
+	defer func() { c.refDepth-- }()
+	return c.resolve(merged, ref)
+}
+
+func (c *jsonSchemaConverter) convertType(
+	value any,
+	subject string,
+	add func(cond, format string, args ...any),
+	unsupported func(keyword, reason string),
+) {
+	names := make([]string, 0)
+	switch v := value.(type) {
+	case string:
+		names = append(names, v)
+	case []any:
+		for _, name := range v {
+			if name, ok := name.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	conditions := make([]string, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "string", "bool", "map", "list":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == %s", subject, name))
+		case "boolean":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == bool", subject))
+		case "integer":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == int", subject))
+		case "number":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == int || type(%s) == double", subject, subject))
+		case "object":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == map", subject))
+		case "array":
+			conditions = append(conditions, fmt.Sprintf("type(%s) == list", subject))
+		case "null":
+			conditions = append(conditions, fmt.Sprintf("%s == null", subject))
+		default:
+			unsupported("type", fmt.Sprintf("unknown type '%s'", name))
+			return
+		}
+	}
+	if len(conditions) == 0 {
+		unsupported("type", "must be a string or a list of strings")
+		return
+	}
+
+	add(strings.Join(conditions, " || "), "must be of type %s", strings.Join(names, " or "))
+}
+
+// convertBound converts minimum and maximum, including the boolean exclusive bounds of draft 4
+func (c *jsonSchemaConverter) convertBound(
+	schema map[string]any,
+	keyword, subject string,
+	add func(cond, format string, args ...any),
+	unsupported func(keyword, reason string),
+) {
+	value := schema[keyword]
+	if _, ok := value.(bool); ok {
+		// Draft 4 exclusive bounds modify minimum and maximum
+		return
+	}
+
+	literal, ok := celLiteral(value)
+	if _, isNumber := value.(json.Number); !ok || !isNumber {
+		unsupported(keyword, "must be a number")
+		return
+	}
+
+	var operator, text string
+	switch keyword {
+	case "minimum":
+		operator, text = ">=", "greater than or equal to"
+		if schema["exclusiveMinimum"] == true {
+			operator, text = ">", "greater than"
+		}
+	case "maximum":
+		operator, text = "<=", "less than or equal to"
+		if schema["exclusiveMaximum"] == true {
+			operator, text = "<", "less than"
+		}
+	case "exclusiveMinimum":
+		operator, text = ">", "greater than"
+	case "exclusiveMaximum":
+		operator, text = "<", "less than"
+	}
+
+	add(fmt.Sprintf("%s %s %s", subject, operator, literal), "must be %s %s", text, literal)
+}
+
+// convertAdditionalProperties restricts the keys of a map to its properties and pattern properties,
+// or validates the values of the other keys against a schema
+func (c *jsonSchemaConverter) convertAdditionalProperties(
+	schema map[string]any,
+	ptr, subject, label string,
+	wrap func(string) string,
+	add func(cond, format string, args ...any),
+	unsupported func(keyword, reason string),
+) {
+	known := make([]string, 0)
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		for _, name := range sortedKeys(properties) {
+			known = append(known, strconv.Quote(name))
+		}
+	}
+
+	key := variable("key", subject)
+	allowed := make([]string, 0)
+	if len(known) > 0 {
+		allowed = append(allowed, fmt.Sprintf("%s in [%s]", key, strings.Join(known, ", ")))
+	}
+	if patterns, ok := schema["patternProperties"].(map[string]any); ok {
+		for _, pattern := range sortedKeys(patterns) {
+			allowed = append(allowed, fmt.Sprintf("%s.matches(%s)", key, strconv.Quote(pattern)))
+		}
+	}
+
+	switch value := schema["additionalProperties"].(type) {
+	case bool:
+		if value {
+			return
+		}
+		if len(allowed) == 0 {
+			add(fmt.Sprintf("size(%s) == 0", subject), "must not have any properties")
+			return
+		}
+		add(
+			fmt.Sprintf("%s.all(%s, %s)", subject, key, strings.Join(allowed, " || ")),
+			"must not have additional properties",
+		)
+	case map[string]any:
+		child := fmt.Sprintf("%s[%s]", subject, key)
+		c.convert(
+			value,
+			ptr+"/additionalProperties",
+			child,
+			label+".*",
+			func(cond string) string {
+				if len(allowed) > 0 {
+					cond = strings.Join(allowed, " || ") + " || " + cond
+				}
+				return wrap(fmt.Sprintf("%s.all(%s, %s)", subject, key, cond))
+			},
+		)
+	default:
+		unsupported("additionalProperties", "must be a boolean or a schema")
+	}
+}
+
+// field returns the CEL expression selecting a property of subject
+func field(subject, name string) string {
+	if isIdentifier(name) {
+		return subject + "." + name
+	}
+	return fmt.Sprintf("%s[%s]", subject, strconv.Quote(name))
+}
+
+// presence returns the CEL expression checking that subject defines a property
+func presence(subject, name string) string {
+	if isIdentifier(name) && !strings.HasSuffix(subject, "]") {
+		return fmt.Sprintf("has(%s.%s)", subject, name)
+	}
+	return fmt.Sprintf("%s in %s", strconv.Quote(name), subject)
+}
+
+func childLabel(label, name string) string {
+	if label == "values" {
+		return name
+	}
+	return label + "." + name
+}
+
+// variable returns a comprehension variable name that is not already used in subject
+func variable(name, subject string) string {
+	used := make(map[string]bool)
+	for _, ident := range identifierPattern.FindAllString(subject, -1) {
+		used[ident] = true
+	}
+
+	v := name
+	for i := 2; used[v]; i++ {
+		v = fmt.Sprintf("%s%d", name, i)
+	}
+	return v
+}
+
+// sizeUnit names what a size keyword counts, in the singular for a bound of 1
+func sizeUnit(keyword string, n int64) string {
+	var singular, plural string
+	switch keyword {
+	case "minLength", "maxLength":
+		singular, plural = "character", "characters"
+	case "minItems", "maxItems":
+		singular, plural = "item", "items"
+	default:
+		singular, plural = "property", "properties"
+	}
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// celLiteral returns the CEL literal of a scalar JSON value
+func celLiteral(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "null", true
+	case bool:
+		return strconv.FormatBool(v), true
+	case string:
+		return strconv.Quote(v), true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String(), true
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return "", false
+		}
+		literal := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(literal, ".e") {
+			literal += ".0"
+		}
+		return literal, true
+	default:
+		return "", false
+	}
+}
+
+func celLiterals(values []any) (string, bool) {
+	literals := make([]string, 0, len(values))
+	for _, value := range values {
+		literal, ok := celLiteral(value)
+		if !ok {
+			return "", false
+		}
+		literals = append(literals, literal)
+	}
+	return strings.Join(literals, ", "), true
+}
+
+func integer(value any) (int64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	n, err := number.Int64()
+	return n, err == nil
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || celKeywords[s] || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// orderedKeywords returns the keywords of a schema in keywordOrder, followed by the others sorted by name
+func orderedKeywords(schema map[string]any) []string {
+	rank := func(keyword string) int {
+		for i, k := range keywordOrder {
+			if k == keyword {
+				return i
+			}
+		}
+		return len(keywordOrder)
+	}
+
+	keywords := sortedKeys(schema)
+	sort.SliceStable(
+		keywords, func(i, j int) bool {
+			return rank(keywords[i]) < rank(keywords[j])
+		},
+	)
+	return keywords
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		schema     string
+		wantRules  []models.Rule
+		wantIssues []Issue
+	}{
+		{
+			name: "required and type",
+			schema: `{
+				"type": "object",
+				"required": ["replicas"],
+				"properties": {"replicas": {"type": "integer"}}
+			}`,
+			wantRules: []models.Rule{
+				{Expr: "has(values.replicas)", Desc: "replicas is required"},
+				{Expr: "!has(values.replicas) || type(values.replicas) == int", Desc: "replicas must be of type integer"},
+			},
+		},
+		{
+			name: "nested constraints are guarded by their parents",
+			schema: `{
+				"properties": {
+					"image": {
+						"properties": {
+							"tag": {"pattern": "^v[0-9]+$", "maxLength": 20},
+							"pullPolicy": {"enum": ["Always", "IfNotPresent"]}
+						}
+					}
+				}
+			}`,
+			wantRules: []models.Rule{
+				{
+					Expr: `!has(values.image) || !has(values.image.pullPolicy) || values.image.pullPolicy in ["Always", "IfNotPresent"]`,
+					Desc: `image.pullPolicy must be one of ["Always", "IfNotPresent"]`,
+				},
+				{
+					Expr: "!has(values.image) || !has(values.image.tag) || size(values.image.tag) <= 20",
+					Desc: "image.tag must have at most 20 characters",
+				},
+				{
+					Expr: `!has(values.image) || !has(values.image.tag) || values.image.tag.matches("^v[0-9]+$")`,
+					Desc: "image.tag must match ^v[0-9]+$",
+				},
+			},
+		},
+		{
+			name: "bounds",
+			schema: `{
+				"properties": {
+					"port": {"exclusiveMinimum": 0, "maximum": 65535},
+					"ratio": {"minimum": 0.5, "maximum": 2, "exclusiveMaximum": true}
+				}
+			}`,
+			wantRules: []models.Rule{
+				{Expr: "!has(values.port) || values.port > 0", Desc: "port must be greater than 0"},
+				{Expr: "!has(values.port) || values.port <= 65535", Desc: "port must be less than or equal to 65535"},
+				{Expr: "!has(values.ratio) || values.ratio >= 0.5", Desc: "ratio must be greater than or equal to 0.5"},
+				{Expr: "!has(values.ratio) || values.ratio < 2", Desc: "ratio must be less than 2"},
+			},
+		},
+		{
+			name: "items and additional properties",
+			schema: `{
+				"properties": {
+					"hosts": {"minItems": 1, "items": {"required": ["host"]}},
+					"labels": {"additionalProperties": {"type": "string"}},
+					"service": {"additionalProperties": false, "properties": {"port": {}}}
+				}
+			}`,
+			wantRules: []models.Rule{
+				{Expr: "!has(values.hosts) || size(values.hosts) >= 1", Desc: "hosts must have at least 1 item"},
+				{Expr: "!has(values.hosts) || values.hosts.all(item, has(item.host))", Desc: "hosts[].host is required"},
+				{
+					Expr: "!has(values.labels) || values.labels.all(key, type(values.labels[key]) == string)",
+					Desc: "labels.* must be of type string",
+				},
+				{
+					Expr: `!has(values.service) || values.service.all(key, key in ["port"])`,
+					Desc: "service must not have additional properties",
+				},
+			},
+		},
+		{
+			name: "size bounds of 1 are singular",
+			schema: `{
+				"properties": {
+					"initial": {"maxLength": 1},
+					"annotations": {"minProperties": 1, "maxProperties": 2}
+				}
+			}`,
+			wantRules: []models.Rule{
+				{Expr: "!has(values.annotations) || size(values.annotations) >= 1", Desc: "annotations must have at least 1 property"},
+				{Expr: "!has(values.annotations) || size(values.annotations) <= 2", Desc: "annotations must have at most 2 properties"},
+				{Expr: "!has(values.initial) || size(values.initial) <= 1", Desc: "initial must have at most 1 character"},
+			},
+		},
+		{
+			name: "references and property names that are not identifiers",
+			schema: `{
+				"properties": {"app.kubernetes.io/name": {"$ref": "#/$defs/name"}},
+				"$defs": {"name": {"type": "string"}}
+			}`,
+			wantRules: []models.Rule{
+				{
+					Expr: `!("app.kubernetes.io/name" in values) || type(values["app.kubernetes.io/name"]) == string`,
+					Desc: "app.kubernetes.io/name must be of type string",
+				},
+			},
+		},
+		{
+			name: "unsupported keywords are reported",
+			schema: `{
+				"title": "values",
+				"properties": {
+					"affinity": {"oneOf": [{"type": "object"}, {"type": "null"}]},
+					"host": {"type": "string", "format": "hostname"},
+					"external": {"$ref": "https://example.com/schema.json"}
+				}
+			}`,
+			wantRules: []models.Rule{
+				{Expr: "!has(values.host) || type(values.host) == string", Desc: "host must be of type string"},
+			},
+			wantIssues: []Issue{
+				{Path: "#/properties/affinity", Keyword: "oneOf"},
+				{Path: "#/properties/external", Keyword: "$ref", Reason: "only local references can be converted"},
+				{Path: "#/properties/host", Keyword: "format"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rules, issues, err := FromJSONSchema([]byte(tt.schema))
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.wantRules, rules.Rules)
+				assert.Equal(t, tt.wantIssues, issues)
+			},
+		)
+	}
+}
+
+func TestFromJSONSchema_InvalidJSON(t *testing.T) {
+	_, _, err := FromJSONSchema([]byte("{"))
+	assert.Error(t, err)
+}
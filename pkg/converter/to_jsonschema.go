@@ -0,0 +1,890 @@
+package converter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/parser"
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+const (
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// itemSegment stands for the items of a list in the paths of all() variables
+	itemSegment = "[]"
+)
+
+// Schema is a JSON Schema generated from rules, keyword order follows the usual layout of values.schema.json
+type Schema struct {
+	SchemaURI        string             `json:"$schema,omitempty"`
+	Type             any                `json:"type,omitempty"` // a type name or a list of type names
+	Required         []string           `json:"required,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	Enum             []any              `json:"enum,omitempty"`
+	Const            any                `json:"const,omitempty"`
+	Minimum          any                `json:"minimum,omitempty"`
+	ExclusiveMinimum any                `json:"exclusiveMinimum,omitempty"`
+	Maximum          any                `json:"maximum,omitempty"`
+	ExclusiveMaximum any                `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int64             `json:"minLength,omitempty"`
+	MaxLength        *int64             `json:"maxLength,omitempty"`
+	MinItems         *int64             `json:"minItems,omitempty"`
+	MaxItems         *int64             `json:"maxItems,omitempty"`
+	MinProperties    *int64             `json:"minProperties,omitempty"`
+	MaxProperties    *int64             `json:"maxProperties,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	Not              *Schema            `json:"not,omitempty"`
+	AllOf            []*Schema          `json:"allOf,omitempty"`
+	XCel             []XCelRule         `json:"x-cel,omitempty"`
+
+	// sizes holds size() bounds until the type is known, see resolveSizes
+	sizes []sizeBound
+}
+
+// XCelRule annotates a schema with a rule that has no JSON Schema equivalent
+type XCelRule struct {
+	ID       string `json:"id,omitempty"`
+	Expr     string `json:"expr"`
+	Desc     string `json:"desc"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// ExportReport summarizes which rules were exported to the schema
+type ExportReport struct {
+	Exported  []string // rules fully expressed as JSON Schema
+	Partial   []string // rules partly expressed as JSON Schema and kept as x-cel annotations
+	Annotated []string // rules only kept as x-cel annotations
+}
+
+type sizeBound struct {
+	min bool
+	n   int64
+}
+
+// constraint is a rule condition translated to JSON Schema, applied to the schema at path
+type constraint struct {
+	path  []string
+	apply func(s *Schema)
+	// implied is the path of a value a condition needs to be present, set for the required entries it implies
+	implied []string
+}
+
+// ToJSONSchema exports enabled rules on values prepared by LoadChartRules to a JSON Schema. Conditions that map cleanly to
+// JSON Schema keywords are exported, everything else, including rules with a severity other than error, is kept
+// as x-cel annotations on the deepest schema covering the values the rule references.
+func ToJSONSchema(rules *models.ValidationRules) (*Schema, *ExportReport, error) {
+	p, err := parser.NewParser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CEL parser: %v", err)
+	}
+
+	parsed := make([]*ast.AST, len(rules.Rules))
+	for i, rule := range rules.Rules {
+		if rule.IsDisabled() || rule.Match != nil {
+			continue
+		}
+		tree, errs := p.Parse(common.NewTextSource(rule.Expr))
+		if len(errs.GetErrors()) > 0 {
+			return nil, nil, fmt.Errorf("failed to parse rule '%s': %v", rule.Desc, errs.ToDisplayString())
+		}
+		parsed[i] = tree
+	}
+
+	// all() is only exported over values known to be lists, as all() over a map iterates over its keys. Lists are
+	// known from type checks exported by any rule, so rules are exported again until no more lists are found.
+	lists := make(map[string]bool)
+	for {
+		root, report := exportRules(rules, parsed, lists)
+		found := root.listPaths(nil, make(map[string]bool))
+		if len(found) == len(lists) {
+			root.resolveSizes()
+			return root, report, nil
+		}
+		lists = found
+	}
+}
+
+// exportRules exports the parsed rules, with all() allowed over the lists
+func exportRules(rules *models.ValidationRules, parsed []*ast.AST, lists map[string]bool) (*Schema, *ExportReport) {
+	root := &Schema{SchemaURI: schemaDraft, Type: "object"}
+	report := &ExportReport{}
+
+	for i, rule := range rules.Rules {
+		// Rules on rendered manifests don't constrain values
+		if parsed[i] == nil {
+			continue
+		}
+		name := rule.ID
+		if name == "" {
+			name = rule.Desc
+		}
+
+		exported, complete := 0, true
+		if rule.Severity == "" || rule.Severity == "error" {
+			roots := map[string][]string{"values": {}}
+			parts := conjuncts(parsed[i].Expr())
+			ruleLists := listChecks(parts, roots, lists)
+			for _, conjunct := range parts {
+				constraints, ok := translate(conjunct, roots, ruleLists)
+				if !ok {
+					complete = false
+					continue
+				}
+				for _, c := range constraints {
+					c.apply(root.at(c.path))
+				}
+				exported++
+			}
+		} else {
+			complete = false
+		}
+
+		switch {
+		case complete:
+			report.Exported = append(report.Exported, name)
+			continue
+		case exported > 0:
+			report.Partial = append(report.Partial, name)
+		default:
+			report.Annotated = append(report.Annotated, name)
+		}
+
+		annotated := root.at(commonPath(parsed[i].Expr()))
+		annotated.XCel = append(
+			annotated.XCel, XCelRule{ID: rule.ID, Expr: rule.Expr, Desc: rule.Desc, Severity: rule.Severity},
+		)
+	}
+
+	return root, report
+}
+
+// listPaths adds the keys of the paths of the schemas of type array to lists
+func (s *Schema) listPaths(path []string, lists map[string]bool) map[string]bool {
+	if s.Type == "array" {
+		lists[pathKey(path)] = true
+	}
+	for name, child := range s.Properties {
+		child.listPaths(append(append([]string{}, path...), name), lists)
+	}
+	if s.Items != nil {
+		s.Items.listPaths(append(append([]string{}, path...), itemSegment), lists)
+	}
+	return lists
+}
+
+// listChecks returns lists with the paths checked by type(x) == list conjuncts, so the other conjuncts can use all()
+func listChecks(parts []ast.Expr, roots map[string][]string, lists map[string]bool) map[string]bool {
+	extended := make(map[string]bool, len(lists))
+	for k := range lists {
+		extended[k] = true
+	}
+	for _, part := range parts {
+		if part.Kind() != ast.CallKind || part.AsCall().FunctionName() != operators.Equals {
+			continue
+		}
+		typeCall, typeName := part.AsCall().Args()[0], part.AsCall().Args()[1]
+		if typeCall.Kind() != ast.CallKind || typeCall.AsCall().FunctionName() != "type" ||
+			len(typeCall.AsCall().Args()) != 1 || typeName.Kind() != ast.IdentKind || typeName.AsIdent() != "list" {
+			continue
+		}
+		if path, ok := valuesPath(typeCall.AsCall().Args()[0], roots); ok {
+			extended[pathKey(path)] = true
+		}
+	}
+	return extended
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// at returns the schema of a values path, creating the object schemas on the way
+func (s *Schema) at(path []string) *Schema {
+	current := s
+	for _, name := range path {
+		if current.Properties == nil {
+			current.Properties = make(map[string]*Schema)
+		}
+		child, ok := current.Properties[name]
+		if !ok {
+			child = &Schema{}
+			current.Properties[name] = child
+		}
+		current = child
+	}
+	return current
+}
+
+// set assigns a keyword through get, moving it to an allOf entry if the keyword is already set to another value
+func (s *Schema) set(get func(s *Schema) *any, value any) {
+	field := get(s)
+	if *field == nil || reflect.DeepEqual(*field, value) {
+		*field = value
+		return
+	}
+	extra := &Schema{}
+	*get(extra) = value
+	s.AllOf = append(s.AllOf, extra)
+}
+
+func (s *Schema) require(name string) {
+	for _, required := range s.Required {
+		if required == name {
+			return
+		}
+	}
+	s.Required = append(s.Required, name)
+}
+
+// resolveSizes turns size() bounds into the length keywords matching the type of each schema,
+// or into all of them if the type is unknown since each only applies to values of its type
+func (s *Schema) resolveSizes() {
+	for _, bound := range s.sizes {
+		n := bound.n
+		switch {
+		case s.Type == "string" && bound.min:
+			s.MinLength = &n
+		case s.Type == "string":
+			s.MaxLength = &n
+		case s.Type == "array" && bound.min:
+			s.MinItems = &n
+		case s.Type == "array":
+			s.MaxItems = &n
+		case s.Type == "object" && bound.min:
+			s.MinProperties = &n
+		case s.Type == "object":
+			s.MaxProperties = &n
+		case bound.min:
+			s.MinLength, s.MinItems, s.MinProperties = &n, &n, &n
+		default:
+			s.MaxLength, s.MaxItems, s.MaxProperties = &n, &n, &n
+		}
+	}
+	s.sizes = nil
+
+	for _, child := range s.Properties {
+		child.resolveSizes()
+	}
+	if s.Items != nil {
+		s.Items.resolveSizes()
+	}
+}
+
+// conjuncts splits an expression on its && operators
+func conjuncts(e ast.Expr) []ast.Expr {
+	if e.Kind() == ast.CallKind && e.AsCall().FunctionName() == operators.LogicalAnd {
+		args := e.AsCall().Args()
+		return append(conjuncts(args[0]), conjuncts(args[1])...)
+	}
+	return []ast.Expr{e}
+}
+
+// disjuncts splits an expression on its || operators
+func disjuncts(e ast.Expr) []ast.Expr {
+	if e.Kind() == ast.CallKind && e.AsCall().FunctionName() == operators.LogicalOr {
+		args := e.AsCall().Args()
+		return append(disjuncts(args[0]), disjuncts(args[1])...)
+	}
+	return []ast.Expr{e}
+}
+
+// translate converts a condition into schema constraints, roots maps the identifiers that can start
+// a values path, values itself and the variables of all() over lists, to their schema path, and lists holds
+// the keys of the paths known to be lists. Conditions on a value require it to be present, like CEL does.
+func translate(e ast.Expr, roots map[string][]string, lists map[string]bool) ([]constraint, bool) {
+	if e.Kind() != ast.CallKind {
+		return nil, false
+	}
+	call := e.AsCall()
+	args := call.Args()
+
+	switch call.FunctionName() {
+	case operators.LogicalAnd:
+		parts := conjuncts(e)
+		lists = listChecks(parts, roots, lists)
+		constraints := make([]constraint, 0)
+		for _, part := range parts {
+			translated, ok := translate(part, roots, lists)
+			if !ok {
+				return nil, false
+			}
+			constraints = append(constraints, translated...)
+		}
+		return constraints, true
+
+	case operators.LogicalOr:
+		// Presence guards, !has(a) || !has(a.b) || condition, match JSON Schema applying constraints to present
+		// properties only, as long as the condition only constrains the guarded properties
+		guards, rest := make([][]string, 0), make([]ast.Expr, 0)
+		for _, disjunct := range disjuncts(e) {
+			if guarded, ok := presenceGuard(disjunct, roots); ok {
+				guards = append(guards, guarded)
+			} else {
+				rest = append(rest, disjunct)
+			}
+		}
+		if len(guards) == 0 {
+			return typeUnion(rest, roots)
+		}
+		var constraints []constraint
+		var ok bool
+		if len(rest) == 1 {
+			constraints, ok = translate(rest[0], roots, lists)
+		} else {
+			constraints, ok = typeUnion(rest, roots)
+		}
+		if !ok {
+			return nil, false
+		}
+		// The guards make the guarded values optional, the values above them are still required and the values
+		// below them are required when the guarded values are present
+		kept := make([]constraint, 0, len(constraints))
+		for _, c := range constraints {
+			if c.implied != nil {
+				if guardedBy(c.implied, guards) {
+					continue
+				}
+				kept = append(kept, c)
+				continue
+			}
+			for _, guarded := range guards {
+				if !within([]constraint{c}, guarded) {
+					return nil, false
+				}
+			}
+			kept = append(kept, c)
+		}
+		return kept, true
+
+	case "has":
+		if len(args) != 1 {
+			return nil, false
+		}
+		path, ok := valuesPath(args[0], roots)
+		if !ok || len(path) == 0 {
+			return nil, false
+		}
+		// Fields are required from the root of the path, values or the item of an all()
+		start := 0
+		for i, name := range path {
+			if name == itemSegment {
+				start = i + 1
+			}
+		}
+		if start == len(path) {
+			return nil, false
+		}
+		constraints := make([]constraint, 0, len(path))
+		for i := start; i < len(path); i++ {
+			parent, name := path[:i], path[i]
+			constraints = append(
+				constraints, constraint{path: parent, apply: func(s *Schema) { s.require(name) }},
+			)
+		}
+		return constraints, true
+
+	case operators.Equals:
+		if constraints, ok := typeUnion([]ast.Expr{e}, roots); ok {
+			return constraints, true
+		}
+		path, literal, ok := comparison(args, roots)
+		if !ok {
+			return nil, false
+		}
+		return append(implied(path), constraint{path: path, apply: func(s *Schema) { s.set(constField, literal) }}), true
+
+	case operators.NotEquals:
+		path, literal, ok := comparison(args, roots)
+		if !ok {
+			return nil, false
+		}
+		return append(
+			implied(path),
+			constraint{path: path, apply: func(s *Schema) { s.AllOf = append(s.AllOf, &Schema{Not: &Schema{Const: literal}}) }},
+		), true
+
+	case operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals:
+		return bound(call.FunctionName(), args, roots)
+
+	case operators.In:
+		path, ok := valuesPath(args[0], roots)
+		if !ok || args[1].Kind() != ast.ListKind {
+			return nil, false
+		}
+		values := make([]any, 0)
+		for _, element := range args[1].AsList().Elements() {
+			value, ok := literalValue(element)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value)
+		}
+		return append(
+			implied(path), constraint{
+				path: path, apply: func(s *Schema) {
+					if s.Enum != nil {
+						s.AllOf = append(s.AllOf, &Schema{Enum: values})
+						return
+					}
+					s.Enum = values
+				},
+			},
+		), true
+
+	case "matches", "startsWith", "endsWith", "contains":
+		target, argument := call.Target(), (ast.Expr)(nil)
+		switch {
+		case call.IsMemberFunction() && len(args) == 1:
+			argument = args[0]
+		case !call.IsMemberFunction() && len(args) == 2 && call.FunctionName() == "matches":
+			target, argument = args[0], args[1]
+		default:
+			return nil, false
+		}
+		path, ok := valuesPath(target, roots)
+		if !ok {
+			return nil, false
+		}
+		value, ok := literalValue(argument)
+		text, isString := value.(string)
+		if !ok || !isString {
+			return nil, false
+		}
+		pattern := text
+		switch call.FunctionName() {
+		case "startsWith":
+			pattern = "^" + regexp.QuoteMeta(text)
+		case "endsWith":
+			pattern = regexp.QuoteMeta(text) + "$"
+		case "contains":
+			pattern = regexp.QuoteMeta(text)
+		}
+		return append(
+			implied(path), constraint{
+				path: path, apply: func(s *Schema) {
+					if s.Pattern != "" && s.Pattern != pattern {
+						s.AllOf = append(s.AllOf, &Schema{Pattern: pattern})
+						return
+					}
+					s.Pattern = pattern
+				},
+			},
+		), true
+
+	case "all":
+		if !call.IsMemberFunction() || len(args) != 2 || args[0].Kind() != ast.IdentKind {
+			return nil, false
+		}
+		path, ok := valuesPath(call.Target(), roots)
+		if !ok || !lists[pathKey(path)] {
+			return nil, false
+		}
+		itemPath := append(append([]string{}, path...), itemSegment)
+		itemRoots := make(map[string][]string, len(roots)+1)
+		for k, v := range roots {
+			itemRoots[k] = v
+		}
+		itemRoots[args[0].AsIdent()] = itemPath
+
+		constraints, ok := translate(args[1], itemRoots, lists)
+		if !ok || !within(constraints, itemPath) {
+			return nil, false
+		}
+		return append(
+			append(implied(path), constraint{path: path, apply: func(s *Schema) { s.set(typeField, "array") }}),
+			itemConstraints(constraints, len(path))...,
+		), true
+	}
+
+	return nil, false
+}
+
+var (
+	typeField  = func(s *Schema) *any { return &s.Type }
+	constField = func(s *Schema) *any { return &s.Const }
+)
+
+// itemConstraints rewrites constraints on the items of the list at depth to apply to its items schema
+func itemConstraints(constraints []constraint, depth int) []constraint {
+	rewritten := make([]constraint, 0, len(constraints))
+	for _, c := range constraints {
+		listPath, rest, apply := c.path[:depth], c.path[depth+1:], c.apply
+		rewritten = append(
+			rewritten, constraint{
+				path:    listPath,
+				implied: c.implied,
+				apply: func(s *Schema) {
+					if s.Items == nil {
+						s.Items = &Schema{}
+					}
+					apply(s.Items.at(rest))
+				},
+			},
+		)
+	}
+	return rewritten
+}
+
+// implied returns the required entries for the fields of path, from its root, values or the item of an all(),
+// since conditions on a missing value fail
+func implied(path []string) []constraint {
+	start := 0
+	for i, name := range path {
+		if name == itemSegment {
+			start = i + 1
+		}
+	}
+	constraints := make([]constraint, 0, len(path)-start)
+	for i := start; i < len(path); i++ {
+		parent, name := append([]string{}, path[:i]...), path[i]
+		constraints = append(
+			constraints,
+			constraint{path: parent, implied: append([]string{}, path[:i+1]...), apply: func(s *Schema) { s.require(name) }},
+		)
+	}
+	return constraints
+}
+
+// guardedBy reports whether a path is one of the guarded paths. The fields below a guarded path are still required
+// when it is present, which their required entries in the schema of the guarded value express.
+func guardedBy(path []string, guards [][]string) bool {
+	for _, guarded := range guards {
+		if reflect.DeepEqual(path, guarded) {
+			return true
+		}
+	}
+	return false
+}
+
+// presenceGuard matches !has(path) and returns the guarded path
+func presenceGuard(e ast.Expr, roots map[string][]string) ([]string, bool) {
+	if e.Kind() != ast.CallKind || e.AsCall().FunctionName() != operators.LogicalNot {
+		return nil, false
+	}
+	inner := e.AsCall().Args()[0]
+	if inner.Kind() != ast.CallKind || inner.AsCall().FunctionName() != "has" || len(inner.AsCall().Args()) != 1 {
+		return nil, false
+	}
+	return valuesPath(inner.AsCall().Args()[0], roots)
+}
+
+// within reports whether every constraint applies to path or one of its descendants
+func within(constraints []constraint, path []string) bool {
+	for _, c := range constraints {
+		if len(c.path) < len(path) || !reflect.DeepEqual(c.path[:len(path)], path) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeUnion matches the disjuncts of type(x) == T checks on the same path
+func typeUnion(checks []ast.Expr, roots map[string][]string) ([]constraint, bool) {
+	var path []string
+	names := make([]string, 0, len(checks))
+
+	for _, check := range checks {
+		if check.Kind() != ast.CallKind || check.AsCall().FunctionName() != operators.Equals {
+			return nil, false
+		}
+		typeCall, typeName := check.AsCall().Args()[0], check.AsCall().Args()[1]
+		if typeCall.Kind() != ast.CallKind || typeCall.AsCall().FunctionName() != "type" ||
+			len(typeCall.AsCall().Args()) != 1 || typeName.Kind() != ast.IdentKind {
+			return nil, false
+		}
+		p, ok := valuesPath(typeCall.AsCall().Args()[0], roots)
+		if !ok || (path != nil && !reflect.DeepEqual(p, path)) {
+			return nil, false
+		}
+		name, ok := jsonType(typeName.AsIdent())
+		if !ok {
+			return nil, false
+		}
+		path = p
+		names = append(names, name)
+	}
+
+	// int || double is a number
+	var value any = names[0]
+	if len(names) > 1 {
+		unique := make([]string, 0, len(names))
+		for _, name := range names {
+			if name == "integer" && contains(names, "number") || contains(unique, name) {
+				continue
+			}
+			unique = append(unique, name)
+		}
+		value = unique
+		if len(unique) == 1 {
+			value = unique[0]
+		}
+	}
+
+	return append(implied(path), constraint{path: path, apply: func(s *Schema) { s.set(typeField, value) }}), true
+}
+
+func jsonType(celType string) (string, bool) {
+	switch celType {
+	case "string":
+		return "string", true
+	case "int", "uint":
+		return "integer", true
+	case "double":
+		return "number", true
+	case "bool":
+		return "boolean", true
+	case "list":
+		return "array", true
+	case "map":
+		return "object", true
+	case "null_type":
+		return "null", true
+	}
+	return "", false
+}
+
+// comparison matches a values path compared with a literal other than null, in either order
+func comparison(args []ast.Expr, roots map[string][]string) ([]string, any, bool) {
+	for _, pair := range [][2]ast.Expr{{args[0], args[1]}, {args[1], args[0]}} {
+		path, ok := valuesPath(pair[0], roots)
+		if !ok {
+			continue
+		}
+		// "const": null can't be told apart from no const once encoded
+		if literal, ok := literalValue(pair[1]); ok && literal != nil {
+			return path, literal, true
+		}
+	}
+	return nil, nil, false
+}
+
+// bound converts comparisons of a value, or of its size(), with a number
+func bound(function string, args []ast.Expr, roots map[string][]string) ([]constraint, bool) {
+	if flipped := map[string]string{
+		operators.Less:          operators.Greater,
+		operators.LessEquals:    operators.GreaterEquals,
+		operators.Greater:       operators.Less,
+		operators.GreaterEquals: operators.LessEquals,
+	}; !isPathOrSize(args[0], roots) {
+		args = []ast.Expr{args[1], args[0]}
+		function = flipped[function]
+	}
+
+	literal, ok := literalValue(args[1])
+	if !ok {
+		return nil, false
+	}
+
+	if path, ok := sizeOf(args[0], roots); ok {
+		n, isInt := literal.(int64)
+		if !isInt {
+			return nil, false
+		}
+		var b sizeBound
+		switch function {
+		case operators.GreaterEquals:
+			b = sizeBound{min: true, n: n}
+		case operators.Greater:
+			b = sizeBound{min: true, n: n + 1}
+		case operators.LessEquals:
+			b = sizeBound{n: n}
+		case operators.Less:
+			b = sizeBound{n: n - 1}
+		}
+		return append(implied(path), constraint{path: path, apply: func(s *Schema) { s.sizes = append(s.sizes, b) }}), true
+	}
+
+	path, ok := valuesPath(args[0], roots)
+	if !ok {
+		return nil, false
+	}
+	switch literal.(type) {
+	case int64, uint64, float64:
+	default:
+		return nil, false
+	}
+
+	var field func(s *Schema) *any
+	switch function {
+	case operators.GreaterEquals:
+		field = func(s *Schema) *any { return &s.Minimum }
+	case operators.Greater:
+		field = func(s *Schema) *any { return &s.ExclusiveMinimum }
+	case operators.LessEquals:
+		field = func(s *Schema) *any { return &s.Maximum }
+	case operators.Less:
+		field = func(s *Schema) *any { return &s.ExclusiveMaximum }
+	}
+	return append(implied(path), constraint{path: path, apply: func(s *Schema) { s.set(field, literal) }}), true
+}
+
+func isPathOrSize(e ast.Expr, roots map[string][]string) bool {
+	if _, ok := valuesPath(e, roots); ok {
+		return true
+	}
+	_, ok := sizeOf(e, roots)
+	return ok
+}
+
+// sizeOf matches size(path) and path.size()
+func sizeOf(e ast.Expr, roots map[string][]string) ([]string, bool) {
+	if e.Kind() != ast.CallKind || e.AsCall().FunctionName() != "size" {
+		return nil, false
+	}
+	call := e.AsCall()
+	switch {
+	case call.IsMemberFunction() && len(call.Args()) == 0:
+		return valuesPath(call.Target(), roots)
+	case !call.IsMemberFunction() && len(call.Args()) == 1:
+		return valuesPath(call.Args()[0], roots)
+	}
+	return nil, false
+}
+
+// valuesPath returns the schema path of a field selection chain, e.g. values.a["b"], rooted at one of roots
+func valuesPath(e ast.Expr, roots map[string][]string) ([]string, bool) {
+	fields := make([]string, 0)
+	for {
+		switch {
+		case e.Kind() == ast.SelectKind:
+			fields = append([]string{e.AsSelect().FieldName()}, fields...)
+			e = e.AsSelect().Operand()
+			continue
+		case e.Kind() == ast.CallKind && e.AsCall().FunctionName() == operators.Index:
+			key, ok := literalValue(e.AsCall().Args()[1])
+			name, isString := key.(string)
+			if !ok || !isString {
+				return nil, false
+			}
+			fields = append([]string{name}, fields...)
+			e = e.AsCall().Args()[0]
+			continue
+		case e.Kind() == ast.IdentKind:
+			root, ok := roots[e.AsIdent()]
+			if !ok {
+				return nil, false
+			}
+			return append(append([]string{}, root...), fields...), true
+		}
+		return nil, false
+	}
+}
+
+// commonPath returns the longest path shared by all values paths in the expression, ignoring list items
+func commonPath(e ast.Expr) []string {
+	var common []string
+	first := true
+
+	var visit func(e ast.Expr)
+	visit = func(e ast.Expr) {
+		if path, ok := valuesPath(e, map[string][]string{"values": {}}); ok {
+			if first {
+				common, first = path, false
+				return
+			}
+			n := 0
+			for n < len(common) && n < len(path) && common[n] == path[n] {
+				n++
+			}
+			common = common[:n]
+			return
+		}
+		switch e.Kind() {
+		case ast.CallKind:
+			if e.AsCall().IsMemberFunction() {
+				visit(e.AsCall().Target())
+			}
+			for _, arg := range e.AsCall().Args() {
+				visit(arg)
+			}
+		case ast.SelectKind:
+			visit(e.AsSelect().Operand())
+		case ast.ListKind:
+			for _, element := range e.AsList().Elements() {
+				visit(element)
+			}
+		case ast.MapKind:
+			for _, entry := range e.AsMap().Entries() {
+				visit(entry.AsMapEntry().Key())
+				visit(entry.AsMapEntry().Value())
+			}
+		}
+	}
+	visit(e)
+
+	return common
+}
+
+// literalValue returns the Go value of a literal, negative numbers are parsed as a negation
+func literalValue(e ast.Expr) (any, bool) {
+	if e.Kind() == ast.CallKind && e.AsCall().FunctionName() == operators.Negate {
+		value, ok := literalValue(e.AsCall().Args()[0])
+		switch v := value.(type) {
+		case int64:
+			return -v, ok
+		case float64:
+			return -v, ok
+		}
+		return nil, false
+	}
+	if e.Kind() != ast.LiteralKind {
+		return nil, false
+	}
+	switch v := e.AsLiteral().(type) {
+	case types.Int:
+		return int64(v), true
+	case types.Uint:
+		return uint64(v), true
+	case types.Double:
+		return float64(v), true
+	case types.String:
+		return string(v), true
+	case types.Bool:
+		return bool(v), true
+	case types.Null:
+		return nil, true
+	}
+	return nil, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the names of the rules in the report, one category per line
+func (r *ExportReport) String() string {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("Exported %d rule(s) to JSON Schema\n", len(r.Exported)))
+	if len(r.Partial) > 0 {
+		msg.WriteString(
+			fmt.Sprintf(
+				"Partly exported %d rule(s), also kept as x-cel annotations: %s\n",
+				len(r.Partial),
+				strings.Join(r.Partial, ", "),
+			),
+		)
+	}
+	if len(r.Annotated) > 0 {
+		msg.WriteString(
+			fmt.Sprintf(
+				"Kept %d rule(s) as x-cel annotations only: %s\n",
+				len(r.Annotated),
+				strings.Join(r.Annotated, ", "),
+			),
+		)
+	}
+	return msg.String()
+}
@@ -0,0 +1,252 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	disabled := true
+	tests := []struct {
+		name       string
+		rules      []models.Rule
+		wantSchema string
+		wantReport ExportReport
+	}{
+		{
+			name: "required, type and bounds",
+			rules: []models.Rule{
+				{
+					ID:   "port",
+					Expr: "has(values.service.port) && type(values.service.port) == int && values.service.port > 0 && 65535 >= values.service.port",
+					Desc: "port must be valid",
+				},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["service"],
+				"properties": {
+					"service": {
+						"required": ["port"],
+						"properties": {
+							"port": {"type": "integer", "exclusiveMinimum": 0, "maximum": 65535}
+						}
+					}
+				}
+			}`,
+			wantReport: ExportReport{Exported: []string{"port"}},
+		},
+		{
+			name: "guarded enum, pattern and size",
+			rules: []models.Rule{
+				{
+					Expr: `!has(values.image) || !has(values.image.pullPolicy) || values.image.pullPolicy in ["Always", "IfNotPresent"]`,
+					Desc: "pull policy must be valid",
+				},
+				{
+					Expr: `type(values.image.tag) == string && values.image.tag.startsWith("v") && size(values.image.tag) <= 20`,
+					Desc: "tag must be valid",
+				},
+				{Expr: "values.tolerations.size() > 0", Desc: "tolerations must not be empty"},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["image", "tolerations"],
+				"properties": {
+					"image": {
+						"required": ["tag"],
+						"properties": {
+							"pullPolicy": {"enum": ["Always", "IfNotPresent"]},
+							"tag": {"type": "string", "maxLength": 20, "pattern": "^v"}
+						}
+					},
+					"tolerations": {"minLength": 1, "minItems": 1, "minProperties": 1}
+				}
+			}`,
+			wantReport: ExportReport{
+				Exported: []string{"pull policy must be valid", "tag must be valid", "tolerations must not be empty"},
+			},
+		},
+		{
+			name: "fields below a guard are required when the guarded value is present",
+			rules: []models.Rule{
+				{
+					Expr: `!has(values.image) || values.image.registry.host.startsWith("registry.example.com")`,
+					Desc: "images must come from the registry",
+				},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"properties": {
+					"image": {
+						"required": ["registry"],
+						"properties": {
+							"registry": {
+								"required": ["host"],
+								"properties": {"host": {"pattern": "^registry\\.example\\.com"}}
+							}
+						}
+					}
+				}
+			}`,
+			wantReport: ExportReport{Exported: []string{"images must come from the registry"}},
+		},
+		{
+			name: "type unions, const and conflicting keywords",
+			rules: []models.Rule{
+				{Expr: "type(values.ratio) == int || type(values.ratio) == double", Desc: "ratio must be a number"},
+				{Expr: "!has(values.scale) || type(values.scale) == int || type(values.scale) == double", Desc: "scale must be a number"},
+				{Expr: `values.mode == "fast"`, Desc: "mode must be fast"},
+				{Expr: `values.mode == "safe"`, Desc: "mode must be safe"},
+				{Expr: `values.name != ""`, Desc: "name must not be empty"},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["ratio", "mode", "name"],
+				"properties": {
+					"mode": {"const": "fast", "allOf": [{"const": "safe"}]},
+					"name": {"allOf": [{"not": {"const": ""}}]},
+					"ratio": {"type": "number"},
+					"scale": {"type": "number"}
+				}
+			}`,
+			wantReport: ExportReport{
+				Exported: []string{
+					"ratio must be a number", "scale must be a number", "mode must be fast", "mode must be safe", "name must not be empty",
+				},
+			},
+		},
+		{
+			name: "list items",
+			rules: []models.Rule{
+				{
+					ID:   "hosts",
+					Expr: `values.ingress.hosts.all(h, has(h.host) && type(h.paths) == list && h.paths.all(p, p.path.startsWith("/")))`,
+					Desc: "hosts must be valid",
+				},
+				{Expr: "type(values.ingress.hosts) == list", Desc: "hosts must be a list"},
+				{Expr: "values.ingress.tls.all(t, t.secretName != '')", Desc: "tls must have secrets"},
+				{Expr: "values.labels.all(key, key in ['app', 'team'])", Desc: "labels must be known"},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["ingress"],
+				"properties": {
+					"ingress": {
+						"required": ["hosts"],
+						"properties": {
+							"hosts": {
+								"type": "array",
+								"items": {
+									"required": ["host", "paths"],
+									"properties": {
+										"paths": {
+											"type": "array",
+											"items": {"required": ["path"], "properties": {"path": {"pattern": "^/"}}}
+										}
+									}
+								}
+							},
+							"tls": {
+								"x-cel": [{"expr": "values.ingress.tls.all(t, t.secretName != '')", "desc": "tls must have secrets"}]
+							}
+						}
+					},
+					"labels": {
+						"x-cel": [{"expr": "values.labels.all(key, key in ['app', 'team'])", "desc": "labels must be known"}]
+					}
+				}
+			}`,
+			wantReport: ExportReport{
+				Exported:  []string{"hosts", "hosts must be a list"},
+				Annotated: []string{"tls must have secrets", "labels must be known"},
+			},
+		},
+		{
+			name: "unmappable conditions and non-error rules are annotated",
+			rules: []models.Rule{
+				{
+					ID:   "replicas",
+					Expr: "values.replicas >= 1 && values.replicas <= values.autoscaling.maxReplicas",
+					Desc: "replicas must be in range",
+				},
+				{ID: "limits", Expr: "has(values.resources.limits) || has(values.resources.requests)", Desc: "resources must be set"},
+				{ID: "debug", Expr: "values.debug == false", Desc: "debug should be off", Severity: "warning"},
+				{ID: "disabled", Expr: "values.x == 1", Desc: "ignored", Disabled: &disabled},
+			},
+			wantSchema: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"required": ["replicas"],
+				"properties": {
+					"debug": {
+						"x-cel": [{"id": "debug", "expr": "values.debug == false", "desc": "debug should be off", "severity": "warning"}]
+					},
+					"replicas": {"minimum": 1},
+					"resources": {
+						"x-cel": [{"id": "limits", "expr": "has(values.resources.limits) || has(values.resources.requests)", "desc": "resources must be set"}]
+					}
+				},
+				"x-cel": [{"id": "replicas", "expr": "values.replicas >= 1 && values.replicas <= values.autoscaling.maxReplicas", "desc": "replicas must be in range"}]
+			}`,
+			wantReport: ExportReport{Partial: []string{"replicas"}, Annotated: []string{"limits", "debug"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				schema, report, err := ToJSONSchema(&models.ValidationRules{Rules: tt.rules})
+				require.NoError(t, err)
+
+				content, err := json.Marshal(schema)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.wantSchema, string(content))
+				assert.Equal(t, tt.wantReport, *report)
+			},
+		)
+	}
+}
+
+func TestToJSONSchema_RoundTrip(t *testing.T) {
+	rules, issues, err := FromJSONSchema(
+		[]byte(`{
+			"type": "object",
+			"required": ["ports"],
+			"additionalProperties": false,
+			"properties": {
+				"ports": {"type": "array", "items": {"type": "integer"}},
+				"labels": {"type": "object", "additionalProperties": false, "properties": {"app": {"type": "string"}}}
+			}
+		}`),
+	)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	schema, _, err := ToJSONSchema(rules)
+	require.NoError(t, err)
+
+	// all() over maps iterates over their keys, so it must not turn them into arrays
+	assert.Equal(t, "object", schema.Type)
+	assert.Empty(t, schema.AllOf)
+	assert.Nil(t, schema.Items)
+	assert.Equal(t, []string{"ports"}, schema.Required)
+	assert.Equal(t, "array", schema.Properties["ports"].Type)
+	assert.Equal(t, &Schema{Type: "integer"}, schema.Properties["ports"].Items)
+	assert.Equal(t, "object", schema.Properties["labels"].Type)
+	assert.Nil(t, schema.Properties["labels"].Items)
+}
+
+func TestToJSONSchema_InvalidRule(t *testing.T) {
+	_, _, err := ToJSONSchema(&models.ValidationRules{Rules: []models.Rule{{Expr: "values.a >", Desc: "broken"}}})
+	assert.ErrorContains(t, err, "failed to parse rule 'broken'")
+}
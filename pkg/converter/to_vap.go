@@ -0,0 +1,356 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/parser"
+	"github.com/idsulik/helm-cel/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+const admissionAPIVersion = "admissionregistration.k8s.io/v1"
+
+// validationActions maps rule severities to the actions of the policy bindings, in the order policies are generated
+var validationActions = []struct {
+	severity string
+	action   string
+}{
+	{"error", "Deny"},
+	{"warning", "Warn"},
+	{"info", "Audit"},
+}
+
+// ValidatingAdmissionPolicy is the subset of the Kubernetes resource generated from rules
+type ValidatingAdmissionPolicy struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   ObjectMeta      `yaml:"metadata"`
+	Spec       AdmissionPolicy `yaml:"spec"`
+}
+
+// AdmissionPolicy is the spec of a ValidatingAdmissionPolicy
+type AdmissionPolicy struct {
+	FailurePolicy    string           `yaml:"failurePolicy"`
+	MatchConstraints MatchConstraints `yaml:"matchConstraints"`
+	Validations      []Validation     `yaml:"validations"`
+}
+
+// MatchConstraints selects the resources a policy applies to
+type MatchConstraints struct {
+	ResourceRules []ResourceRule `yaml:"resourceRules"`
+}
+
+// ResourceRule matches operations on resources of the given groups and versions
+type ResourceRule struct {
+	APIGroups   []string `yaml:"apiGroups"`
+	APIVersions []string `yaml:"apiVersions"`
+	Operations  []string `yaml:"operations"`
+	Resources   []string `yaml:"resources"`
+}
+
+// Validation is a CEL expression checked on admission
+type Validation struct {
+	Expression  string `yaml:"expression"`
+	Message     string `yaml:"message"`
+	MessageExpr string `yaml:"messageExpr"`
+}
+
+// ValidatingAdmissionPolicyBinding is the subset of the Kubernetes resource generated from rules
+type ValidatingAdmissionPolicyBinding struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   ObjectMeta    `yaml:"metadata"`
+	Spec       PolicyBinding `yaml:"spec"`
+}
+
+// PolicyBinding is the spec of a ValidatingAdmissionPolicyBinding
+type PolicyBinding struct {
+	PolicyName        string   `yaml:"policyName"`
+	ValidationActions []string `yaml:"validationActions"`
+}
+
+// ObjectMeta holds the name of a generated resource
+type ObjectMeta struct {
+	Name string `yaml:"name"`
+}
+
+// AdmissionExport holds the policies generated from rules and the rules that could not be exported
+type AdmissionExport struct {
+	Policies      []ValidatingAdmissionPolicy
+	Bindings      []ValidatingAdmissionPolicyBinding
+	NonExportable []NonExportableRule
+}
+
+// NonExportableRule is a rule that can't be enforced at admission time
+type NonExportableRule struct {
+	Rule   string // rule ID, or description if the rule has no ID
+	Reason string
+}
+
+func (r NonExportableRule) String() string {
+	return fmt.Sprintf("rule '%s': %s", r.Rule, r.Reason)
+}
+
+// ToValidatingAdmissionPolicies exports enabled rules prepared by LoadChartRules as ValidatingAdmissionPolicy
+// and binding manifests, using the admission resources of the rules to rewrite values paths into fields of
+// the admitted object. A policy is generated per resource and severity, with bindings denying errors,
+// warning about warnings and auditing infos. Rules referencing values without a mapping, or the values of
+// several resources, are reported as non-exportable.
+func ToValidatingAdmissionPolicies(rules *models.ValidationRules, name string) (*AdmissionExport, error) {
+	p, err := parser.NewParser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL parser: %v", err)
+	}
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType), cel.Variable("oldObject", cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+	}
+
+	var resources []models.AdmissionResource
+	if rules.Admission != nil {
+		resources = rules.Admission.Resources
+	}
+
+	// Validations by resource index and severity
+	validations := make(map[int]map[string][]Validation)
+	export := &AdmissionExport{}
+
+	for _, rule := range rules.Rules {
+		if rule.IsDisabled() {
+			continue
+		}
+		ruleName := rule.ID
+		if ruleName == "" {
+			ruleName = rule.Desc
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = "error"
+		}
+		if rule.Match != nil {
+			export.NonExportable = append(
+				export.NonExportable,
+				NonExportableRule{Rule: ruleName, Reason: "rules on rendered manifests have no admission resource"},
+			)
+			continue
+		}
+
+		parsed, errs := p.Parse(common.NewTextSource(rule.Expr))
+		if len(errs.GetErrors()) > 0 {
+			return nil, fmt.Errorf("failed to parse rule '%s': %v", rule.Desc, errs.ToDisplayString())
+		}
+
+		r := &objectRewriter{resources: resources, resource: -1}
+		r.rewrite(parsed.Expr())
+		if r.reason == "" && r.resource < 0 {
+			r.reason = "references no values"
+		}
+		if r.reason != "" {
+			export.NonExportable = append(export.NonExportable, NonExportableRule{Rule: ruleName, Reason: r.reason})
+			continue
+		}
+
+		expression, err := parser.Unparse(parsed.Expr(), parsed.SourceInfo(), parser.WrapOnOperators())
+		if err != nil {
+			return nil, fmt.Errorf("failed to print rule '%s': %v", rule.Desc, err)
+		}
+		if _, issues := env.Compile(expression); issues != nil && issues.Err() != nil {
+			export.NonExportable = append(
+				export.NonExportable, NonExportableRule{
+					Rule:   ruleName,
+					Reason: fmt.Sprintf("rewritten expression %s is invalid: %v", expression, issues.Err()),
+				},
+			)
+			continue
+		}
+
+		message := rule.Desc
+		if rule.ID != "" {
+			message = fmt.Sprintf("[%s] %s", rule.ID, rule.Desc)
+		}
+		if validations[r.resource] == nil {
+			validations[r.resource] = make(map[string][]Validation)
+		}
+		validations[r.resource][severity] = append(
+			validations[r.resource][severity], Validation{
+				Expression:  expression,
+				Message:     message,
+				MessageExpr: fmt.Sprintf("%s + object.metadata.name", strconv.Quote(message+": ")),
+			},
+		)
+	}
+
+	for i, resource := range resources {
+		for _, mapping := range validationActions {
+			policyValidations := validations[i][mapping.severity]
+			if len(policyValidations) == 0 {
+				continue
+			}
+
+			policyName := fmt.Sprintf("%s-%s-%s", name, resource.Name, mapping.severity)
+			export.Policies = append(
+				export.Policies, ValidatingAdmissionPolicy{
+					APIVersion: admissionAPIVersion,
+					Kind:       "ValidatingAdmissionPolicy",
+					Metadata:   ObjectMeta{Name: policyName},
+					Spec: AdmissionPolicy{
+						FailurePolicy: "Fail",
+						MatchConstraints: MatchConstraints{
+							ResourceRules: []ResourceRule{
+								{
+									APIGroups:   resource.APIGroups,
+									APIVersions: resource.APIVersions,
+									Operations:  []string{"CREATE", "UPDATE"},
+									Resources:   resource.Resources,
+								},
+							},
+						},
+						Validations: policyValidations,
+					},
+				},
+			)
+			export.Bindings = append(
+				export.Bindings, ValidatingAdmissionPolicyBinding{
+					APIVersion: admissionAPIVersion,
+					Kind:       "ValidatingAdmissionPolicyBinding",
+					Metadata:   ObjectMeta{Name: policyName},
+					Spec:       PolicyBinding{PolicyName: policyName, ValidationActions: []string{mapping.action}},
+				},
+			)
+		}
+	}
+
+	return export, nil
+}
+
+// WriteManifests writes the policies, each followed by its binding, as a multi-document YAML stream
+func (e *AdmissionExport) WriteManifests(w io.Writer) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for i := range e.Policies {
+		if err := encoder.Encode(e.Policies[i]); err != nil {
+			return fmt.Errorf("failed to marshal policy to YAML: %v", err)
+		}
+		if err := encoder.Encode(e.Bindings[i]); err != nil {
+			return fmt.Errorf("failed to marshal policy binding to YAML: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to marshal policies to YAML: %v", err)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// objectRewriter replaces the values paths of a rule with the fields of a single admission resource
+type objectRewriter struct {
+	resources []models.AdmissionResource
+	resource  int
+	reason    string
+}
+
+func (r *objectRewriter) rewrite(e ast.Expr) {
+	if r.reason != "" {
+		return
+	}
+
+	if path, ok := valuesPath(e, map[string][]string{"values": {}}); ok && len(path) > 0 {
+		r.replace(e, path)
+		return
+	}
+
+	switch e.Kind() {
+	case ast.IdentKind:
+		if e.AsIdent() == "values" {
+			r.reason = "uses values outside of a field selection"
+		}
+	case ast.CallKind:
+		call := e.AsCall()
+		if call.IsMemberFunction() {
+			r.rewrite(call.Target())
+		}
+		for _, arg := range call.Args() {
+			r.rewrite(arg)
+		}
+	case ast.SelectKind:
+		r.rewrite(e.AsSelect().Operand())
+	case ast.ListKind:
+		for _, element := range e.AsList().Elements() {
+			r.rewrite(element)
+		}
+	case ast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			r.rewrite(entry.AsMapEntry().Key())
+			r.rewrite(entry.AsMapEntry().Value())
+		}
+	}
+}
+
+// replace substitutes the field mapped to the longest prefix of the values path
+func (r *objectRewriter) replace(e ast.Expr, path []string) {
+	resource, target, depth := -1, "", 0
+	for i, candidate := range r.resources {
+		for key, field := range candidate.Values {
+			prefix := strings.Split(strings.TrimPrefix(key, "values."), ".")
+			if len(prefix) <= depth || len(prefix) > len(path) || !equalPaths(prefix, path[:len(prefix)]) {
+				continue
+			}
+			resource, target, depth = i, field, len(prefix)
+		}
+	}
+
+	valuesPath := "values." + strings.Join(path, ".")
+	switch {
+	case resource < 0:
+		r.reason = fmt.Sprintf("%s has no admission mapping", valuesPath)
+		return
+	case r.resource >= 0 && r.resource != resource:
+		names := []string{r.resources[r.resource].Name, r.resources[resource].Name}
+		sort.Strings(names)
+		r.reason = fmt.Sprintf("references values of several resources (%s)", strings.Join(names, ", "))
+		return
+	}
+	r.resource = resource
+
+	for _, field := range path[depth:] {
+		if isIdentifier(field) {
+			target += "." + field
+		} else {
+			target += "[" + strconv.Quote(field) + "]"
+		}
+	}
+
+	p, err := parser.NewParser()
+	if err != nil {
+		r.reason = fmt.Sprintf("failed to create CEL parser: %v", err)
+		return
+	}
+	replacement, errs := p.Parse(common.NewTextSource(target))
+	if len(errs.GetErrors()) > 0 {
+		r.reason = fmt.Sprintf("invalid admission mapping %s for %s", target, valuesPath)
+		return
+	}
+	e.SetKindCase(replacement.Expr())
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAdmission = &models.Admission{
+	Resources: []models.AdmissionResource{
+		{
+			Name:        "deployment",
+			APIGroups:   []string{"apps"},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"deployments"},
+			Values: map[string]string{
+				"replicaCount":     "object.spec.replicas",
+				"image":            "object.spec.template.spec.containers[0]",
+				"values.podLabels": "object.spec.template.metadata.labels",
+			},
+		},
+		{
+			Name:        "service",
+			APIGroups:   []string{""},
+			APIVersions: []string{"v1"},
+			Resources:   []string{"services"},
+			Values:      map[string]string{"service.port": "object.spec.ports[0].port"},
+		},
+	},
+}
+
+func TestToValidatingAdmissionPolicies(t *testing.T) {
+	disabled := true
+	tests := []struct {
+		name              string
+		rules             []models.Rule
+		wantPolicies      map[string][]Validation
+		wantActions       map[string][]string
+		wantNonExportable []NonExportableRule
+	}{
+		{
+			name: "rules are grouped by resource and severity",
+			rules: []models.Rule{
+				{ID: "replicas", Expr: "values.replicaCount >= 2", Desc: "replicas must be highly available"},
+				{Expr: `has(values.image.imagePullPolicy) && values.image.imagePullPolicy != "Never"`, Desc: "pull policy must be set"},
+				{ID: "port", Expr: "values.service.port != 80", Desc: "port should not be 80", Severity: "warning"},
+				{ID: "labels", Expr: `"app" in values.podLabels`, Desc: "app label is recommended", Severity: "info"},
+				{ID: "disabled", Expr: "values.replicaCount < 100", Desc: "ignored", Disabled: &disabled},
+			},
+			wantPolicies: map[string][]Validation{
+				"mychart-deployment-error": {
+					{
+						Expression:  "object.spec.replicas >= 2",
+						Message:     "[replicas] replicas must be highly available",
+						MessageExpr: `"[replicas] replicas must be highly available: " + object.metadata.name`,
+					},
+					{
+						Expression:  `has(object.spec.template.spec.containers[0].imagePullPolicy) && object.spec.template.spec.containers[0].imagePullPolicy != "Never"`,
+						Message:     "pull policy must be set",
+						MessageExpr: `"pull policy must be set: " + object.metadata.name`,
+					},
+				},
+				"mychart-deployment-info": {
+					{
+						Expression:  `"app" in object.spec.template.metadata.labels`,
+						Message:     "[labels] app label is recommended",
+						MessageExpr: `"[labels] app label is recommended: " + object.metadata.name`,
+					},
+				},
+				"mychart-service-warning": {
+					{
+						Expression:  "object.spec.ports[0].port != 80",
+						Message:     "[port] port should not be 80",
+						MessageExpr: `"[port] port should not be 80: " + object.metadata.name`,
+					},
+				},
+			},
+			wantActions: map[string][]string{
+				"mychart-deployment-error": {"Deny"},
+				"mychart-deployment-info":  {"Audit"},
+				"mychart-service-warning":  {"Warn"},
+			},
+		},
+		{
+			name: "non-exportable rules",
+			rules: []models.Rule{
+				{ID: "unmapped", Expr: "!values.debug", Desc: "debug must be disabled"},
+				{ID: "mixed", Expr: "values.replicaCount > 1 || values.service.port > 0", Desc: "mixed"},
+				{ID: "constant", Expr: "1 < 2", Desc: "constant"},
+				{ID: "whole", Expr: "size(values) > 0", Desc: "values must be set"},
+			},
+			wantNonExportable: []NonExportableRule{
+				{Rule: "unmapped", Reason: "values.debug has no admission mapping"},
+				{Rule: "mixed", Reason: "references values of several resources (deployment, service)"},
+				{Rule: "constant", Reason: "references no values"},
+				{Rule: "whole", Reason: "uses values outside of a field selection"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				export, err := ToValidatingAdmissionPolicies(
+					&models.ValidationRules{Rules: tt.rules, Admission: testAdmission},
+					"mychart",
+				)
+				require.NoError(t, err)
+
+				policies := make(map[string][]Validation)
+				for _, policy := range export.Policies {
+					assert.Equal(t, "ValidatingAdmissionPolicy", policy.Kind)
+					policies[policy.Metadata.Name] = policy.Spec.Validations
+				}
+				actions := make(map[string][]string)
+				for _, binding := range export.Bindings {
+					assert.Equal(t, binding.Metadata.Name, binding.Spec.PolicyName)
+					actions[binding.Spec.PolicyName] = binding.Spec.ValidationActions
+				}
+
+				if tt.wantPolicies == nil {
+					tt.wantPolicies = map[string][]Validation{}
+					tt.wantActions = map[string][]string{}
+				}
+				assert.Equal(t, tt.wantPolicies, policies)
+				assert.Equal(t, tt.wantActions, actions)
+				assert.Equal(t, tt.wantNonExportable, export.NonExportable)
+			},
+		)
+	}
+}
+
+func TestAdmissionExport_WriteManifests(t *testing.T) {
+	export, err := ToValidatingAdmissionPolicies(
+		&models.ValidationRules{
+			Rules:     []models.Rule{{ID: "replicas", Expr: "values.replicaCount >= 2", Desc: "replicas must be highly available"}},
+			Admission: testAdmission,
+		},
+		"mychart",
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteManifests(&buf))
+	assert.Equal(
+		t, `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: mychart-deployment-error
+spec:
+  failurePolicy: Fail
+  matchConstraints:
+    resourceRules:
+      - apiGroups:
+          - apps
+        apiVersions:
+          - v1
+        operations:
+          - CREATE
+          - UPDATE
+        resources:
+          - deployments
+  validations:
+    - expression: object.spec.replicas >= 2
+      message: '[replicas] replicas must be highly available'
+      messageExpr: '"[replicas] replicas must be highly available: " + object.metadata.name'
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: mychart-deployment-error
+spec:
+  policyName: mychart-deployment-error
+  validationActions:
+    - Deny
+`, buf.String(),
+	)
+}
@@ -0,0 +1,228 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+)
+
+const (
+	// Ways rules can be grouped in the documentation
+	GroupByFile = "file"
+	GroupByTag  = "tag"
+
+	// Markers delimiting the generated section in an existing document such as the chart README
+	StartMarker = "<!-- helm-cel:rules:start -->"
+	EndMarker   = "<!-- helm-cel:rules:end -->"
+
+	untaggedGroup = "Untagged"
+)
+
+// Group is a titled set of documented rules
+type Group struct {
+	Name  string
+	Rules []RuleDoc
+}
+
+// RuleDoc is the documentation of a single rule
+type RuleDoc struct {
+	ID          string
+	Description string
+	Severity    string
+	Expression  string
+	Values      []string
+}
+
+// Build documents the enabled rules prepared by LoadChartRules, grouped by rules file, relative to
+// the chart path, or by tag. A rule with several tags is listed in each of their groups.
+func Build(rules *models.ValidationRules, chartPath, groupBy string) ([]Group, error) {
+	if groupBy != GroupByFile && groupBy != GroupByTag {
+		return nil, fmt.Errorf("invalid group by '%s' (must be one of %s, %s)", groupBy, GroupByFile, GroupByTag)
+	}
+
+	groups := make([]Group, 0)
+	index := make(map[string]int)
+	add := func(name string, doc RuleDoc) {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, Group{Name: name})
+		}
+		groups[i].Rules = append(groups[i].Rules, doc)
+	}
+
+	for _, rule := range rules.Rules {
+		if rule.IsDisabled() {
+			continue
+		}
+
+		doc, err := newRuleDoc(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		switch groupBy {
+		case GroupByTag:
+			if len(rule.Tags) == 0 {
+				add(untaggedGroup, doc)
+			}
+			for _, tag := range rule.Tags {
+				add(tag, doc)
+			}
+		default:
+			file := rule.File
+			if rel, err := filepath.Rel(chartPath, file); err == nil && file != "" {
+				file = rel
+			}
+			add(file, doc)
+		}
+	}
+
+	if groupBy == GroupByTag {
+		sort.SliceStable(
+			groups, func(i, j int) bool {
+				if groups[i].Name == untaggedGroup || groups[j].Name == untaggedGroup {
+					return groups[j].Name == untaggedGroup && groups[i].Name != untaggedGroup
+				}
+				return groups[i].Name < groups[j].Name
+			},
+		)
+	}
+
+	return groups, nil
+}
+
+func newRuleDoc(rule models.Rule) (RuleDoc, error) {
+	severity := rule.Severity
+	if severity == "" {
+		severity = validator.ErrorSeverity
+	}
+	expression := rule.Source
+	if expression == "" {
+		expression = rule.Expr
+	}
+
+	paths, err := validator.ValuesPaths(rule.Expr)
+	if err != nil {
+		return RuleDoc{}, fmt.Errorf("failed to document rule '%s': %v", rule.Desc, err)
+	}
+
+	return RuleDoc{
+		ID:          rule.ID,
+		Description: rule.Desc,
+		Severity:    severity,
+		Expression:  expression,
+		Values:      paths,
+	}, nil
+}
+
+// WriteMarkdown writes the groups as a Markdown section with a table per group
+func WriteMarkdown(w io.Writer, groups []Group) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("## Validation Rules\n")
+	if len(groups) == 0 {
+		buf.WriteString("\nNo validation rules.\n")
+	}
+	for _, group := range groups {
+		buf.WriteString(fmt.Sprintf("\n### %s\n\n", group.Name))
+		buf.WriteString("| ID | Description | Severity | Expression | Values |\n")
+		buf.WriteString("|----|-------------|----------|------------|--------|\n")
+		for _, rule := range group.Rules {
+			id := "-"
+			if rule.ID != "" {
+				id = "`" + rule.ID + "`"
+			}
+			values := make([]string, 0, len(rule.Values))
+			for _, path := range rule.Values {
+				values = append(values, "`"+path+"`")
+			}
+			buf.WriteString(
+				fmt.Sprintf(
+					"| %s | %s | %s | `%s` | %s |\n",
+					id,
+					markdownCell(rule.Description),
+					rule.Severity,
+					markdownCell(rule.Expression),
+					strings.Join(values, ", "),
+				),
+			)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// markdownCell escapes text so that it fits in a single table cell
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+var htmlTemplate = template.Must(
+	template.New("docs").Parse(
+		`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Validation Rules</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code { font-size: 0.9em; }
+.error { color: #c62828; }
+.warning { color: #ef6c00; }
+.info { color: #1565c0; }
+</style>
+</head>
+<body>
+<h1>Validation Rules</h1>
+{{- if not . }}
+<p>No validation rules.</p>
+{{- end }}
+{{- range . }}
+<h2>{{ .Name }}</h2>
+<table>
+<tr><th>ID</th><th>Description</th><th>Severity</th><th>Expression</th><th>Values</th></tr>
+{{- range .Rules }}
+<tr><td>{{ if .ID }}<code>{{ .ID }}</code>{{ else }}-{{ end }}</td><td>{{ .Description }}</td><td class="{{ .Severity }}">{{ .Severity }}</td><td><code>{{ .Expression }}</code></td><td>{{ range $i, $path := .Values }}{{ if $i }}, {{ end }}<code>{{ $path }}</code>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`,
+	),
+)
+
+// WriteHTML writes the groups as a standalone HTML page with a table per group
+func WriteHTML(w io.Writer, groups []Group) error {
+	return htmlTemplate.Execute(w, groups)
+}
+
+// Inject replaces the content between StartMarker and EndMarker in a document with the section
+func Inject(document, section []byte) ([]byte, error) {
+	start := bytes.Index(document, []byte(StartMarker))
+	end := bytes.Index(document, []byte(EndMarker))
+	if start < 0 || end < 0 || end < start {
+		return nil, fmt.Errorf("markers %s and %s not found", StartMarker, EndMarker)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(document[:start+len(StartMarker)])
+	buf.WriteString("\n")
+	buf.Write(section)
+	buf.Write(document[end:])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package docs
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRules(chartPath string) *models.ValidationRules {
+	disabled := true
+	return &models.ValidationRules{
+		Rules: []models.Rule{
+			{
+				ID:       "replicas-ha",
+				Expr:     "values.replicas >= 2",
+				Desc:     "replicas should be highly available",
+				Severity: "warning",
+				Tags:     []string{"scaling"},
+				File:     filepath.Join(chartPath, "values.cel.yaml"),
+			},
+			{
+				Expr:   "((values.service.port >= 1 && values.service.port <= 65535))",
+				Source: "${validPort(values.service.port)}",
+				Desc:   "port must be valid | in range",
+				File:   filepath.Join(chartPath, "values.cel.yaml"),
+			},
+			{
+				ID:       "debug",
+				Expr:     "!values.debug",
+				Desc:     "debug must be disabled",
+				Disabled: &disabled,
+				File:     filepath.Join(chartPath, "values.cel.yaml"),
+			},
+			{
+				ID:   "ingress-hosts",
+				Expr: "values.ingress.enabled ? size(values.ingress.hosts) > 0 : true",
+				Desc: "ingress needs hosts",
+				Tags: []string{"network", "scaling"},
+				File: filepath.Join(chartPath, "rules", "ingress.cel.yaml"),
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	chartPath := t.TempDir()
+
+	t.Run(
+		"group by file", func(t *testing.T) {
+			groups, err := Build(testRules(chartPath), chartPath, GroupByFile)
+			require.NoError(t, err)
+
+			require.Len(t, groups, 2)
+			assert.Equal(t, "values.cel.yaml", groups[0].Name)
+			assert.Equal(
+				t, []RuleDoc{
+					{
+						ID:          "replicas-ha",
+						Description: "replicas should be highly available",
+						Severity:    "warning",
+						Expression:  "values.replicas >= 2",
+						Values:      []string{"values.replicas"},
+					},
+					{
+						Description: "port must be valid | in range",
+						Severity:    "error",
+						Expression:  "${validPort(values.service.port)}",
+						Values:      []string{"values.service.port"},
+					},
+				}, groups[0].Rules,
+			)
+			assert.Equal(t, filepath.Join("rules", "ingress.cel.yaml"), groups[1].Name)
+			assert.Equal(
+				t, []string{"values.ingress.enabled", "values.ingress.hosts"}, groups[1].Rules[0].Values,
+			)
+		},
+	)
+
+	t.Run(
+		"group by tag", func(t *testing.T) {
+			groups, err := Build(testRules(chartPath), chartPath, GroupByTag)
+			require.NoError(t, err)
+
+			names := make([]string, 0)
+			for _, group := range groups {
+				names = append(names, group.Name)
+			}
+			assert.Equal(t, []string{"network", "scaling", "Untagged"}, names)
+			assert.Len(t, groups[1].Rules, 2)
+		},
+	)
+
+	t.Run(
+		"invalid group by", func(t *testing.T) {
+			_, err := Build(testRules(chartPath), chartPath, "severity")
+			assert.Error(t, err)
+		},
+	)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	chartPath := t.TempDir()
+	groups, err := Build(testRules(chartPath), chartPath, GroupByFile)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, groups))
+
+	assert.Contains(t, buf.String(), "## Validation Rules\n\n### values.cel.yaml\n\n| ID | Description |")
+	assert.Contains(
+		t,
+		buf.String(),
+		"| `replicas-ha` | replicas should be highly available | warning | `values.replicas >= 2` | `values.replicas` |\n",
+	)
+	assert.Contains(
+		t,
+		buf.String(),
+		"| - | port must be valid \\| in range | error | `${validPort(values.service.port)}` | `values.service.port` |\n",
+	)
+	assert.NotContains(t, buf.String(), "debug must be disabled")
+}
+
+func TestInject(t *testing.T) {
+	document := []byte("# Chart\n\n" + StartMarker + "\nold\n" + EndMarker + "\n\nMore docs\n")
+
+	updated, err := Inject(document, []byte("new\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Chart\n\n"+StartMarker+"\nnew\n"+EndMarker+"\n\nMore docs\n", string(updated))
+
+	_, err = Inject([]byte("# Chart\n"), []byte("new\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/parser"
+)
+
+const (
+	// Identifiers standing in for ${name}, ${name(args)} and $N while an expression is parsed
+	referencePrefix = "__r"
+	callPrefix      = "__c"
+	parameterPrefix = "__p"
+)
+
+var placeholderPattern = regexp.MustCompile(`__([rcp])(\d+)\b`)
+
+// FormatExpression pretty-prints a CEL expression through its parsed AST, normalizing spacing,
+// quotes and parentheses. Named expression references and parameters are kept as written.
+func FormatExpression(expr string) (string, error) {
+	names := make([]string, 0)
+	encoded, err := encodePlaceholders(expr, &names)
+	if err != nil {
+		return "", err
+	}
+
+	// Macros are not expanded so that has($0) and friends parse and print back as calls
+	p, err := parser.NewParser()
+	if err != nil {
+		return "", fmt.Errorf("failed to create CEL parser: %v", err)
+	}
+	parsed, errs := p.Parse(common.NewTextSource(encoded))
+	if len(errs.GetErrors()) > 0 {
+		return "", fmt.Errorf("failed to parse expression: %v", errs.ToDisplayString())
+	}
+
+	// Expressions are kept on a single line, the unparser wraps long && and || chains by default
+	unparsed, err := parser.Unparse(parsed.Expr(), parsed.SourceInfo(), parser.WrapOnOperators())
+	if err != nil {
+		return "", fmt.Errorf("failed to print expression: %v", err)
+	}
+
+	return decodePlaceholders(unparsed, names)
+}
+
+// encodePlaceholders replaces ${name(args)} references with calls to placeholder functions and
+// $N parameters with placeholder identifiers, so that the expression is valid CEL
+func encodePlaceholders(expr string, names *[]string) (string, error) {
+	var out strings.Builder
+	quote := byte(0)
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(expr) {
+				i++
+				out.WriteByte(expr[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			out.WriteByte(c)
+		case c == '$' && i+1 < len(expr) && expr[i+1] == '{':
+			end := i + 2
+			for end < len(expr) && isIdentChar(expr[end]) {
+				end++
+			}
+			name := expr[i+2 : end]
+
+			if end < len(expr) && expr[end] == '(' {
+				closing := matchingParen(expr, end)
+				if closing < 0 {
+					return "", fmt.Errorf("unbalanced parentheses in %s", expr[i:])
+				}
+				out.WriteString(fmt.Sprintf("%s%d", callPrefix, len(*names)))
+				*names = append(*names, name)
+				args, err := encodePlaceholders(expr[end+1:closing], names)
+				if err != nil {
+					return "", err
+				}
+				out.WriteString("(" + args + ")")
+				end = closing + 1
+			} else {
+				out.WriteString(fmt.Sprintf("%s%d", referencePrefix, len(*names)))
+				*names = append(*names, name)
+			}
+
+			if end >= len(expr) || expr[end] != '}' {
+				return "", fmt.Errorf("malformed reference %s", expr[i:])
+			}
+			i = end
+		case c == '$' && i+1 < len(expr) && expr[i+1] >= '0' && expr[i+1] <= '9':
+			end := i + 1
+			for end < len(expr) && expr[end] >= '0' && expr[end] <= '9' {
+				end++
+			}
+			out.WriteString(parameterPrefix + expr[i+1:end])
+			i = end - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.String(), nil
+}
+
+// decodePlaceholders restores the references and parameters replaced by encodePlaceholders
+func decodePlaceholders(expr string, names []string) (string, error) {
+	var out strings.Builder
+	quote := byte(0)
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(expr) {
+				i++
+				out.WriteByte(expr[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			out.WriteByte(c)
+			continue
+		}
+
+		loc := placeholderPattern.FindStringSubmatchIndex(expr[i:])
+		if loc == nil || loc[0] != 0 || (i > 0 && isIdentChar(expr[i-1])) {
+			out.WriteByte(c)
+			continue
+		}
+
+		kind := expr[i+loc[2] : i+loc[3]]
+		index, _ := strconv.Atoi(expr[i+loc[4] : i+loc[5]])
+		end := i + loc[1]
+
+		if kind == "p" {
+			out.WriteString(fmt.Sprintf("$%d", index))
+			i = end - 1
+			continue
+		}
+
+		if index >= len(names) {
+			return "", fmt.Errorf("unknown placeholder %s", expr[i:end])
+		}
+		out.WriteString("${" + names[index])
+		if kind == "c" {
+			if end >= len(expr) || expr[end] != '(' {
+				return "", fmt.Errorf("missing arguments of placeholder %s", expr[i:end])
+			}
+			closing := matchingParen(expr, end)
+			if closing < 0 {
+				return "", fmt.Errorf("unbalanced parentheses in %s", expr[i:])
+			}
+			args, err := decodePlaceholders(expr[end+1:closing], names)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString("(" + args + ")")
+			end = closing + 1
+		}
+		out.WriteString("}")
+		i = end - 1
+	}
+
+	return out.String(), nil
+}
+
+// matchingParen returns the index of the parenthesis closing the one at start, skipping quoted strings
+func matchingParen(expr string, start int) int {
+	depth := 0
+	quote := byte(0)
+	for i := start; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
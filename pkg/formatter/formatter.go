@@ -0,0 +1,254 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Canonical order of keys, keys not listed keep their relative order after the listed ones
+	topLevelKeyOrder   = []string{"expressions", "rules", "profiles", "admission"}
+	ruleKeyOrder       = []string{"id", "match", "expr", "desc", "severity", "tags", "disabled"}
+	expressionKeyOrder = []string{"expr", "override"}
+	overrideKeyOrder   = []string{"severity", "disabled"}
+)
+
+// Formatter rewrites rules files into a canonical layout
+type Formatter struct{}
+
+// New creates a new Formatter instance
+func New() *Formatter {
+	return &Formatter{}
+}
+
+// Format returns the canonical form of a rules file, preserving its comments.
+// Expressions that are not valid CEL are kept as written.
+func (f *Formatter) Format(content []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %v", err)
+	}
+	if doc.Kind == 0 {
+		return content, nil
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("rules file must contain a mapping")
+	}
+
+	root := doc.Content[0]
+	if len(root.Content) > 0 {
+		first := root.Content[0]
+		sortKeys(root, topLevelKeyOrder)
+		// A comment at the top of the file that is not separated by a blank line is attached to the first key,
+		// it stays at the top when that key moves
+		if root.Content[0] != first && doc.HeadComment == "" && first.HeadComment != "" {
+			doc.HeadComment, first.HeadComment = first.HeadComment, ""
+		}
+	}
+
+	if expressions := mappingValue(root, "expressions"); expressions != nil && expressions.Kind == yaml.MappingNode {
+		f.formatExpressions(expressions)
+	}
+	if rules := mappingValue(root, "rules"); rules != nil && rules.Kind == yaml.SequenceNode {
+		for _, rule := range rules.Content {
+			if rule.Kind == yaml.MappingNode {
+				f.formatRule(rule)
+			}
+		}
+	}
+	if profiles := mappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		f.formatProfiles(profiles)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode rules file: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode rules file: %v", err)
+	}
+
+	return addBlankLines(buf.Bytes()), nil
+}
+
+// formatExpressions sorts named expressions by name and formats their CEL
+func (f *Formatter) formatExpressions(expressions *yaml.Node) {
+	pairs := keyValuePairs(expressions)
+	sort.SliceStable(
+		pairs, func(i, j int) bool {
+			return pairs[i][0].Value < pairs[j][0].Value
+		},
+	)
+	expressions.Content = flatten(pairs)
+
+	for _, pair := range pairs {
+		setStyle(pair[0], 0)
+		value := pair[1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			formatCEL(value)
+		case yaml.MappingNode:
+			sortKeys(value, expressionKeyOrder)
+			if expr := mappingValue(value, "expr"); expr != nil {
+				formatCEL(expr)
+			}
+		}
+	}
+}
+
+// formatRule orders the keys of a rule and normalizes the quoting of its values
+func (f *Formatter) formatRule(rule *yaml.Node) {
+	sortKeys(rule, ruleKeyOrder)
+
+	for _, pair := range keyValuePairs(rule) {
+		key, value := pair[0], pair[1]
+		setStyle(key, 0)
+		if value.Kind != yaml.ScalarNode {
+			continue
+		}
+		switch key.Value {
+		case "expr":
+			formatCEL(value)
+		case "desc":
+			setStyle(value, yaml.DoubleQuotedStyle)
+		case "id", "severity", "disabled":
+			setStyle(value, 0)
+		}
+	}
+}
+
+// formatProfiles orders the keys of each rule override in the profiles
+func (f *Formatter) formatProfiles(profiles *yaml.Node) {
+	for _, profile := range keyValuePairs(profiles) {
+		rules := mappingValue(profile[1], "rules")
+		if rules == nil || rules.Kind != yaml.MappingNode {
+			continue
+		}
+		for _, override := range keyValuePairs(rules) {
+			if override[1].Kind == yaml.MappingNode {
+				sortKeys(override[1], overrideKeyOrder)
+			}
+		}
+	}
+}
+
+// formatCEL pretty-prints the CEL expression of a scalar node, single-quoted or as a literal block if it spans lines
+func formatCEL(node *yaml.Node) {
+	if formatted, err := FormatExpression(node.Value); err == nil {
+		node.Value = formatted
+	}
+	if strings.Contains(node.Value, "\n") {
+		setStyle(node, yaml.LiteralStyle)
+	} else {
+		setStyle(node, yaml.SingleQuotedStyle)
+	}
+}
+
+// setStyle sets the style of a string scalar, leaving other tags such as booleans untouched
+func setStyle(node *yaml.Node, style yaml.Style) {
+	if node.Kind != yaml.ScalarNode {
+		return
+	}
+	if style != 0 && node.Tag != "" && node.Tag != "!!str" {
+		return
+	}
+	node.Style = style
+}
+
+// sortKeys reorders the keys of a mapping, keys in order come first, others keep their relative order
+func sortKeys(mapping *yaml.Node, order []string) {
+	rank := func(key string) int {
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	pairs := keyValuePairs(mapping)
+	sort.SliceStable(
+		pairs, func(i, j int) bool {
+			return rank(pairs[i][0].Value) < rank(pairs[j][0].Value)
+		},
+	)
+	mapping.Content = flatten(pairs)
+}
+
+// mappingValue returns the value node of a key in a mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func keyValuePairs(mapping *yaml.Node) [][2]*yaml.Node {
+	pairs := make([][2]*yaml.Node, 0, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{mapping.Content[i], mapping.Content[i+1]})
+	}
+	return pairs
+}
+
+func flatten(pairs [][2]*yaml.Node) []*yaml.Node {
+	content := make([]*yaml.Node, 0, len(pairs)*2)
+	for _, pair := range pairs {
+		content = append(content, pair[0], pair[1])
+	}
+	return content
+}
+
+// addBlankLines separates top-level keys and the items of top-level sequences with a blank line,
+// which the YAML encoder does not preserve. Comments stay attached to the line that follows them.
+func addBlankLines(content []byte) []byte {
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	out := make([]string, 0, len(lines))
+
+	inSequence := false
+	for i, line := range lines {
+		previous := ""
+		if i > 0 {
+			previous = lines[i-1]
+		}
+
+		topLevel := line != "" && line[0] != ' '
+		item := strings.HasPrefix(line, "  - ") || strings.HasPrefix(line, "  #")
+
+		switch {
+		case previous == "":
+		case topLevel && !strings.HasPrefix(previous, "#"):
+			out = append(out, "")
+		case inSequence && item && !strings.HasPrefix(previous, "  #") && previous[0] == ' ':
+			out = append(out, "")
+		}
+
+		if topLevel && !strings.HasPrefix(line, "#") {
+			inSequence = startsSequence(lines[i+1:])
+		}
+		out = append(out, line)
+	}
+
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// startsSequence reports whether the lines following a top-level key are the items of a sequence
+func startsSequence(lines []string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "  #") {
+			return strings.HasPrefix(line, "  - ")
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "normalizes spacing and quotes",
+			expr: "values.image.tag!='latest'&&has( values.image )",
+			want: `values.image.tag != "latest" && has(values.image)`,
+		},
+		{
+			name: "removes redundant parentheses",
+			expr: "((values.replicas > 1))",
+			want: "values.replicas > 1",
+		},
+		{
+			name: "keeps macros",
+			expr: "values.hosts.all(h,h.endsWith('.com'))",
+			want: `values.hosts.all(h, h.endsWith(".com"))`,
+		},
+		{
+			name: "keeps references and parameters",
+			expr: "${inRange( $0,1 , 65535 )}&&${hasPort}",
+			want: "${inRange($0, 1, 65535)} && ${hasPort}",
+		},
+		{
+			name: "nested references",
+			expr: "${hasField(${root}.service)}",
+			want: "${hasField(${root}.service)}",
+		},
+		{
+			name: "references inside strings are untouched",
+			expr: "values.a=='${b}'",
+			want: `values.a == "${b}"`,
+		},
+		{
+			name:    "invalid expression",
+			expr:    "values.replicas >>",
+			wantErr: true,
+		},
+		{
+			name:    "malformed reference",
+			expr:    "${inRange(values.a}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := FormatExpression(tt.expr)
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
+
+func TestFormatter_Format(t *testing.T) {
+	input := `# Rules for the chart
+
+rules:
+# Image
+- desc: image tag must be pinned  # no latest
+  severity: "warning"
+  expr: "values.image.tag!='latest'"
+  id: "image-tag"
+- expr: ${validPort(values.service.port)}
+  desc: 'port must be valid'
+  disabled: true
+- expr: values.replicas >>
+  desc: broken
+profiles:
+  prod:
+    rules:
+      image-tag:
+        disabled: false
+        severity: error
+expressions:
+  # Port range
+  validPort: "$0>=1 && $0<=65535"
+  hasField:
+    override: true
+    expr: has($0)
+`
+
+	want := `# Rules for the chart
+
+expressions:
+  hasField:
+    expr: 'has($0)'
+    override: true
+  # Port range
+  validPort: '$0 >= 1 && $0 <= 65535'
+
+rules:
+  # Image
+  - id: image-tag
+    expr: 'values.image.tag != "latest"'
+    desc: "image tag must be pinned" # no latest
+    severity: warning
+
+  - expr: '${validPort(values.service.port)}'
+    desc: "port must be valid"
+    disabled: true
+
+  - expr: 'values.replicas >>'
+    desc: "broken"
+
+profiles:
+  prod:
+    rules:
+      image-tag:
+        severity: error
+        disabled: false
+`
+
+	f := New()
+	got, err := f.Format([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+
+	again, err := f.Format(got)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(again), "formatting must be idempotent")
+}
+
+func TestFormatter_FormatHeadComment(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "comment on the first key stays at the top",
+			input: `# Rules for the chart
+rules:
+  - expr: "${positive}"
+    desc: "replicas must be positive"
+# Shared expressions
+expressions:
+  positive: "values.replicas > 0"
+`,
+			want: `# Rules for the chart
+
+# Shared expressions
+expressions:
+  positive: 'values.replicas > 0'
+
+rules:
+  - expr: '${positive}'
+    desc: "replicas must be positive"
+`,
+		},
+		{
+			name: "comment of the first key moves with it",
+			input: `# Rules for the chart
+
+# Replicas
+rules:
+  - expr: "${positive}"
+    desc: "replicas must be positive"
+expressions:
+  positive: "values.replicas > 0"
+`,
+			want: `# Rules for the chart
+
+expressions:
+  positive: 'values.replicas > 0'
+
+# Replicas
+rules:
+  - expr: '${positive}'
+    desc: "replicas must be positive"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				f := New()
+				got, err := f.Format([]byte(tt.input))
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, string(got))
+
+				again, err := f.Format(got)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, string(again), "formatting must be idempotent")
+			},
+		)
+	}
+}
+
+func TestFormatter_FormatInvalid(t *testing.T) {
+	_, err := New().Format([]byte("- not a mapping"))
+	assert.Error(t, err)
+}
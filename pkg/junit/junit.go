@@ -0,0 +1,87 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// TestSuites is the root element of a JUnit XML report
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     float64     `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite groups related test cases, e.g. all cases of one file
+type TestSuite struct {
+	Name       string      `xml:"name,attr"`
+	Tests      int         `xml:"tests,attr"`
+	Failures   int         `xml:"failures,attr"`
+	Skipped    int         `xml:"skipped,attr"`
+	Time       float64     `xml:"time,attr"`
+	Properties *Properties `xml:"properties,omitempty"`
+	Cases      []TestCase  `xml:"testcase"`
+}
+
+// TestCase is a single test within a suite
+type TestCase struct {
+	Name       string      `xml:"name,attr"`
+	Classname  string      `xml:"classname,attr"`
+	Time       float64     `xml:"time,attr"`
+	Properties *Properties `xml:"properties,omitempty"`
+	Failure    *Failure    `xml:"failure,omitempty"`
+	Skipped    *Skipped    `xml:"skipped,omitempty"`
+}
+
+// Properties wraps the properties of a suite or test case
+type Properties struct {
+	Property []Property `xml:"property"`
+}
+
+// Property is a name/value pair attached to a suite or test case
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Failure describes why a test case failed
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Skipped marks a test case as skipped
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// Add appends a suite and updates the totals of the report
+func (s *TestSuites) Add(suite TestSuite) {
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Skipped += suite.Skipped
+	s.Time += suite.Time
+}
+
+// Write encodes the report as indented XML including the XML header
+func (s *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %v", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -3,46 +3,465 @@ package models
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Rule represents a single CEL validation rule with severity and name
 type Rule struct {
-	Expr     string `yaml:"expr"`
-	Desc     string `yaml:"desc"`
-	Severity string `yaml:"severity,omitempty"` // "error" or "warning", defaults to "error"
+	ID       string   `yaml:"id,omitempty"`
+	Expr     string   `yaml:"expr"`
+	Desc     string   `yaml:"desc"`
+	Severity string   `yaml:"severity,omitempty"` // "error", "warning" or "info", defaults to "error"
+	Tags     []string `yaml:"tags,omitempty"`
+	Match    *Match   `yaml:"match,omitempty"`    // set for rules on rendered manifests, exposed as object
+	Disabled *bool    `yaml:"disabled,omitempty"` // unset keeps the state of an overridden rule
+
+	// File is the rules file the rule was loaded from, set by the rules loader
+	File string `yaml:"-"`
+	// Source is the expression as written, before named expressions were expanded into Expr
+	Source string `yaml:"-"`
+}
+
+// IsDisabled reports whether the rule is disabled and must not be evaluated
+func (r Rule) IsDisabled() bool {
+	return r.Disabled != nil && *r.Disabled
+}
+
+// Match selects the rendered manifests a rule applies to, empty lists match any manifest
+type Match struct {
+	APIVersions []string `yaml:"apiVersions,omitempty"`
+	Kinds       []string `yaml:"kinds,omitempty"`
+}
+
+// Matches reports whether the manifest has one of the API versions and kinds
+func (m *Match) Matches(manifest map[string]any) bool {
+	return matchesAny(m.APIVersions, manifest["apiVersion"]) && matchesAny(m.Kinds, manifest["kind"])
+}
+
+func matchesAny(candidates []string, value any) bool {
+	if len(candidates) == 0 {
+		return true
+	}
+	for _, candidate := range candidates {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
 }
 
 // ValidationRules contains all CEL validation rules and named expressions
 type ValidationRules struct {
-	Rules       []Rule            `yaml:"rules"`
-	Expressions map[string]string `yaml:"expressions,omitempty"`
+	Rules       []Rule             `yaml:"rules"`
+	Expressions map[string]string  `yaml:"expressions,omitempty"`
+	Profiles    map[string]Profile `yaml:"profiles,omitempty"`
+	Admission   *Admission         `yaml:"admission,omitempty"`
+
+	// ExpressionOverrides holds the names of expressions allowed to replace
+	// an expression with the same name from an earlier rules file
+	ExpressionOverrides map[string]bool `yaml:"-"`
+}
+
+// NamedExpression is the object form of a named expression, e.g. {expr: "...", override: true}
+type NamedExpression struct {
+	Expr     string `yaml:"expr"`
+	Override bool   `yaml:"override,omitempty"`
+}
+
+// UnmarshalYAML accepts named expressions both as plain strings and in object form
+func (r *ValidationRules) UnmarshalYAML(node *yaml.Node) error {
+	// Decode every field but the expressions as usual, the expressions are converted below
+	rest := *node
+	var expressions *yaml.Node
+	if node.Kind == yaml.MappingNode {
+		rest.Content = make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "expressions" {
+				expressions = node.Content[i+1]
+				continue
+			}
+			rest.Content = append(rest.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+
+	type plain ValidationRules
+	if err := rest.Decode((*plain)(r)); err != nil {
+		return err
+	}
+
+	r.Expressions = nil
+	r.ExpressionOverrides = nil
+	if expressions == nil {
+		return nil
+	}
+
+	var named map[string]NamedExpression
+	if err := expressions.Decode(&named); err != nil {
+		return err
+	}
+	for name, expr := range named {
+		if r.Expressions == nil {
+			r.Expressions = make(map[string]string)
+		}
+		r.Expressions[name] = expr.Expr
+		if expr.Override {
+			if r.ExpressionOverrides == nil {
+				r.ExpressionOverrides = make(map[string]bool)
+			}
+			r.ExpressionOverrides[name] = true
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalYAML accepts a named expression either as a plain string or in object form
+func (e *NamedExpression) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Expr = node.Value
+		return nil
+	}
+
+	type plain NamedExpression
+	return node.Decode((*plain)(e))
+}
+
+// Profile overrides rules by ID when selected, e.g. to make a rule stricter in production
+type Profile struct {
+	Rules map[string]RuleOverride `yaml:"rules"`
+}
+
+// RuleOverride changes the severity of a rule or disables it within a profile
+type RuleOverride struct {
+	Severity string `yaml:"severity,omitempty"`
+	Disabled *bool  `yaml:"disabled,omitempty"`
+}
+
+// Admission maps values to the fields of the resources rendered by the chart, so that rules
+// can be exported as admission policies
+type Admission struct {
+	Resources []AdmissionResource `yaml:"resources"`
+}
+
+// AdmissionResource maps values paths to CEL expressions on the object of a kind of resource,
+// e.g. replicaCount: object.spec.replicas
+type AdmissionResource struct {
+	Name        string            `yaml:"name"`
+	APIGroups   []string          `yaml:"apiGroups"`
+	APIVersions []string          `yaml:"apiVersions"`
+	Resources   []string          `yaml:"resources"`
+	Values      map[string]string `yaml:"values"`
 }
 
 // ValidationResult represents the outcome of validation
 type ValidationResult struct {
 	Errors   []*ValidationError `json:"errors" yaml:"errors"`
 	Warnings []*ValidationError `json:"warnings" yaml:"warnings"`
+	Infos    []*ValidationError `json:"infos" yaml:"infos"`
+	Passed   []*PassedRule      `json:"passed,omitempty" yaml:"passed,omitempty"`
+	Skipped  []*SkippedRule     `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Duration time.Duration      `json:"-" yaml:"-"` // time spent evaluating the rules
+}
+
+// SkippedRule records a rule that was not evaluated and why
+type SkippedRule struct {
+	RuleID      string `json:"rule_id,omitempty" yaml:"rule_id,omitempty"`
+	Description string `json:"description" yaml:"description"`
+	Expression  string `json:"expression" yaml:"expression"`
+	Reason      string `json:"reason" yaml:"reason"`
+	RuleIndex   int    `json:"-" yaml:"-"` // position in the merged rules, tells apart rules without ID
+}
+
+// Summary counts the rules of a validation by outcome, a rule on rendered manifests fails if it fails on any manifest
+type Summary struct {
+	Rules      int     `json:"rules" yaml:"rules"`
+	Passed     int     `json:"passed" yaml:"passed"`
+	Failed     int     `json:"failed" yaml:"failed"`
+	Skipped    int     `json:"skipped" yaml:"skipped"`
+	Errors     int     `json:"errors" yaml:"errors"`
+	Warnings   int     `json:"warnings" yaml:"warnings"`
+	Infos      int     `json:"infos" yaml:"infos"`
+	DurationMs float64 `json:"duration_ms" yaml:"duration_ms"`
+}
+
+// Add returns the sum of two summaries, e.g. of several charts
+func (s Summary) Add(other Summary) Summary {
+	return Summary{
+		Rules:      s.Rules + other.Rules,
+		Passed:     s.Passed + other.Passed,
+		Failed:     s.Failed + other.Failed,
+		Skipped:    s.Skipped + other.Skipped,
+		Errors:     s.Errors + other.Errors,
+		Warnings:   s.Warnings + other.Warnings,
+		Infos:      s.Infos + other.Infos,
+		DurationMs: s.DurationMs + other.DurationMs,
+	}
+}
+
+// PassedRule records a rule that evaluated to true, once per matching manifest for rules on rendered manifests
+type PassedRule struct {
+	RuleID      string `json:"rule_id,omitempty" yaml:"rule_id,omitempty"`
+	Description string `json:"description" yaml:"description"`
+	Expression  string `json:"expression" yaml:"expression"`
+	Resource    string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	RuleIndex   int    `json:"-" yaml:"-"` // position in the merged rules, tells apart rules without ID
 }
 
 // ValidationError represents a validation failure
 type ValidationError struct {
+	RuleID      string `json:"rule_id,omitempty" yaml:"rule_id,omitempty"`
 	Description string `json:"description" yaml:"description"`
 	Expression  string `json:"expression" yaml:"expression"`
 	Value       any    `json:"value" yaml:"value"`
 	Path        string `json:"path,omitempty" yaml:"path,omitempty"`
+	Resource    string `json:"resource,omitempty" yaml:"resource,omitempty"` // rendered manifest, e.g. Deployment/default/app
+	RuleIndex   int    `json:"-" yaml:"-"`                                   // position in the merged rules, tells apart rules without ID
+	Invalid     bool   `json:"-" yaml:"-"`                                   // the rule does not compile and was not evaluated
+}
+
+// LintIssue describes a problem found in a rules file without evaluating any values
+type LintIssue struct {
+	File     string `json:"file,omitempty" yaml:"file,omitempty"`
+	Rule     string `json:"rule,omitempty" yaml:"rule,omitempty"` // rule ID, or description if the rule has no ID
+	Check    string `json:"check" yaml:"check"`
+	Severity string `json:"severity" yaml:"severity"` // "error" or "warning"
+	Message  string `json:"message" yaml:"message"`
+}
+
+// LintOutput is used for structured output of the lint command
+type LintOutput struct {
+	HasErrors   bool         `json:"has_errors" yaml:"has_errors"`
+	HasWarnings bool         `json:"has_warnings" yaml:"has_warnings"`
+	Issues      []*LintIssue `json:"issues" yaml:"issues"`
+}
+
+func (i *LintIssue) String() string {
+	var msg strings.Builder
+	if i.File != "" {
+		msg.WriteString(i.File + ": ")
+	}
+	msg.WriteString(fmt.Sprintf("%s [%s]", i.Severity, i.Check))
+	if i.Rule != "" {
+		msg.WriteString(fmt.Sprintf(" rule '%s'", i.Rule))
+	}
+	msg.WriteString(": " + i.Message)
+	return msg.String()
+}
+
+// EvalOutput is the result of evaluating a single expression against values
+type EvalOutput struct {
+	Expression string `json:"expression" yaml:"expression"`
+	Expanded   string `json:"expanded,omitempty" yaml:"expanded,omitempty"` // set if named expressions were expanded
+	Value      any    `json:"value" yaml:"value"`
+	Type       string `json:"type" yaml:"type"`
+}
+
+// Explanation describes how a single rule is expanded and evaluated against values
+type Explanation struct {
+	ID             string           `json:"id,omitempty" yaml:"id,omitempty"`
+	Description    string           `json:"description" yaml:"description"`
+	Severity       string           `json:"severity" yaml:"severity"`
+	Expression     string           `json:"expression" yaml:"expression"`
+	Expanded       string           `json:"expanded" yaml:"expanded"`
+	Steps          []ExpansionStep  `json:"steps,omitempty" yaml:"steps,omitempty"`
+	Values         []ValueReference `json:"values,omitempty" yaml:"values,omitempty"`
+	Subexpressions []Subexpression  `json:"subexpressions,omitempty" yaml:"subexpressions,omitempty"`
+	Passed         bool             `json:"passed" yaml:"passed"`
+	Result         any              `json:"result" yaml:"result"`
+	Error          string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ExpansionStep is a single substitution of a named expression reference
+type ExpansionStep struct {
+	Reference   string `json:"reference" yaml:"reference"`     // e.g. ${minReplicas(2)}
+	Replacement string `json:"replacement" yaml:"replacement"` // the named expression with its arguments applied
+	Result      string `json:"result" yaml:"result"`           // the whole expression after the substitution
+}
+
+// ValueReference is a values path referenced by an expression and its current value
+type ValueReference struct {
+	Path  string `json:"path" yaml:"path"`
+	Value any    `json:"value" yaml:"value"`
+	Found bool   `json:"found" yaml:"found"`
+}
+
+// Subexpression is the evaluation result of a part of an expression
+type Subexpression struct {
+	Expression string `json:"expression" yaml:"expression"`
+	Value      any    `json:"value" yaml:"value"`
+}
+
+func (e *Explanation) String() string {
+	var msg strings.Builder
+
+	if e.ID != "" {
+		msg.WriteString(fmt.Sprintf("ID: %s\n", e.ID))
+	}
+	msg.WriteString(fmt.Sprintf("Description: %s\n", e.Description))
+	msg.WriteString(fmt.Sprintf("Severity: %s\n", e.Severity))
+	msg.WriteString(fmt.Sprintf("Expression: %s\n", e.Expression))
+
+	if len(e.Steps) > 0 {
+		msg.WriteString("\nExpansion:\n")
+		for i, step := range e.Steps {
+			msg.WriteString(fmt.Sprintf("  %d. %s => %s\n", i+1, step.Reference, step.Replacement))
+			msg.WriteString(fmt.Sprintf("     %s\n", step.Result))
+		}
+		msg.WriteString(fmt.Sprintf("Expanded: %s\n", e.Expanded))
+	}
+
+	if len(e.Values) > 0 {
+		msg.WriteString("\nValues:\n")
+		for _, ref := range e.Values {
+			if ref.Found {
+				msg.WriteString(fmt.Sprintf("  %s = %s\n", ref.Path, formatValue(ref.Value)))
+			} else {
+				msg.WriteString(fmt.Sprintf("  %s (not set)\n", ref.Path))
+			}
+		}
+	}
+
+	if len(e.Subexpressions) > 0 {
+		msg.WriteString("\nEvaluation:\n")
+		for _, sub := range e.Subexpressions {
+			msg.WriteString(fmt.Sprintf("  %s => %s\n", sub.Expression, formatValue(sub.Value)))
+		}
+	}
+
+	msg.WriteString("\n")
+	switch {
+	case e.Error != "":
+		msg.WriteString(fmt.Sprintf("Result: ❌ error: %s\n", e.Error))
+	case e.Passed:
+		msg.WriteString(fmt.Sprintf("Result: ✅ %s\n", formatValue(e.Result)))
+	default:
+		msg.WriteString(fmt.Sprintf("Result: ❌ %s\n", formatValue(e.Result)))
+	}
+
+	return msg.String()
+}
+
+// formatValue renders a value on a single line, using flow-style YAML for lists and maps
+func formatValue(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", value)
+	case map[string]any, []any:
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		setFlowStyle(node)
+		out, err := yaml.Marshal(node)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return strings.TrimSpace(string(out))
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func setFlowStyle(node *yaml.Node) {
+	node.Style |= yaml.FlowStyle
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
 }
 
 // ValidationOutput is used for structured output in JSON/YAML format
 type ValidationOutput struct {
 	HasErrors   bool              `json:"has_errors" yaml:"has_errors"`
 	HasWarnings bool              `json:"has_warnings" yaml:"has_warnings"`
+	HasInfos    bool              `json:"has_infos" yaml:"has_infos"`
+	Summary     Summary           `json:"summary" yaml:"summary"`
 	Result      *ValidationResult `json:"result" yaml:"result"`
 }
 
+// ChartsOutput is used for structured output of the validation of several charts, keyed by chart path
+type ChartsOutput struct {
+	HasErrors   bool                        `json:"has_errors" yaml:"has_errors"`
+	HasWarnings bool                        `json:"has_warnings" yaml:"has_warnings"`
+	HasInfos    bool                        `json:"has_infos" yaml:"has_infos"`
+	Summary     Summary                     `json:"summary" yaml:"summary"`
+	Charts      map[string]ValidationOutput `json:"charts" yaml:"charts"`
+}
+
+// Environment is a named stack of values files validated together in a matrix, in order of precedence
+type Environment struct {
+	Name        string
+	ValuesFiles []string
+}
+
+// MatrixOutput is used for structured output of a matrix validation, with the outcome of each rule side by side
+// and the result of each environment keyed by its name
+type MatrixOutput struct {
+	HasErrors    bool                        `json:"has_errors" yaml:"has_errors"`
+	HasWarnings  bool                        `json:"has_warnings" yaml:"has_warnings"`
+	HasInfos     bool                        `json:"has_infos" yaml:"has_infos"`
+	Summary      Summary                     `json:"summary" yaml:"summary"`
+	Rules        []MatrixRule                `json:"rules" yaml:"rules"`
+	Environments map[string]ValidationOutput `json:"environments" yaml:"environments"`
+}
+
+// MatrixRule is the outcome of a rule in each environment: error, warning or info if it failed, passed or skipped
+type MatrixRule struct {
+	ID          string            `json:"id,omitempty" yaml:"id,omitempty"`
+	Description string            `json:"description" yaml:"description"`
+	Expression  string            `json:"expression" yaml:"expression"`
+	Results     map[string]string `json:"results" yaml:"results"`
+}
+
 func (vr *ValidationResult) HasErrors() bool {
 	return len(vr.Errors) > 0
 }
 
+// Summary counts the passed, failed and skipped rules of the result
+func (vr *ValidationResult) Summary() Summary {
+	failed := make(map[string]bool)
+	for _, failures := range [][]*ValidationError{vr.Errors, vr.Warnings, vr.Infos} {
+		for _, failure := range failures {
+			failed[resultKey(failure.RuleID, failure.RuleIndex)] = true
+		}
+	}
+	passed := make(map[string]bool)
+	for _, pass := range vr.Passed {
+		if key := resultKey(pass.RuleID, pass.RuleIndex); !failed[key] {
+			passed[key] = true
+		}
+	}
+
+	skipped := make(map[string]bool)
+	for _, skip := range vr.Skipped {
+		skipped[resultKey(skip.RuleID, skip.RuleIndex)] = true
+	}
+
+	summary := Summary{
+		Passed:     len(passed),
+		Failed:     len(failed),
+		Skipped:    len(skipped),
+		Errors:     len(vr.Errors),
+		Warnings:   len(vr.Warnings),
+		Infos:      len(vr.Infos),
+		DurationMs: float64(vr.Duration.Microseconds()) / 1000,
+	}
+	summary.Rules = summary.Passed + summary.Failed + summary.Skipped
+	return summary
+}
+
+// resultKey identifies the rule of a result by ID, or by position for rules without one, since several rules
+// without ID can share an expression
+func resultKey(ruleID string, ruleIndex int) string {
+	if ruleID != "" {
+		return "id:" + ruleID
+	}
+	return fmt.Sprintf("index:%d", ruleIndex)
+}
+
 func (vr *ValidationResult) Error() string {
 	var msg strings.Builder
 
@@ -69,6 +488,19 @@ func (vr *ValidationResult) Error() string {
 		}
 	}
 
+	if len(vr.Infos) > 0 {
+		if len(vr.Errors) > 0 || len(vr.Warnings) > 0 {
+			msg.WriteString("\n\n")
+		}
+		msg.WriteString(fmt.Sprintf("Found %d info(s):\n\n", len(vr.Infos)))
+		for i, info := range vr.Infos {
+			msg.WriteString(info.Info())
+			if i < len(vr.Infos)-1 {
+				msg.WriteString("\n\n")
+			}
+		}
+	}
+
 	return msg.String()
 }
 
@@ -80,9 +512,19 @@ func (e *ValidationError) Warning() string {
 	return e.format("⚠️")
 }
 
+func (e *ValidationError) Info() string {
+	return e.format("ℹ️")
+}
+
 func (e *ValidationError) format(symbol string) string {
 	var msg strings.Builder
 	msg.WriteString(fmt.Sprintf("%s %s\n", symbol, e.Description))
+	if e.RuleID != "" {
+		msg.WriteString(fmt.Sprintf("   ID: %s\n", e.RuleID))
+	}
+	if e.Resource != "" {
+		msg.WriteString(fmt.Sprintf("   Resource: %s\n", e.Resource))
+	}
 	msg.WriteString(fmt.Sprintf("   Rule: %s\n", e.Expression))
 	if e.Path != "" {
 		msg.WriteString(fmt.Sprintf("   Path: %s\n", e.Path))
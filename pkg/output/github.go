@@ -0,0 +1,67 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// formatGitHub writes each failure as a GitHub Actions workflow command, annotating the line of the values
+// file setting the failing value
+func formatGitHub(w io.Writer, report *Report) error {
+	rules := newRuleIndex(report.Rules)
+	locator := NewValuesLocator(report.ValuesFiles)
+
+	for _, failures := range []struct {
+		command string
+		errors  []*models.ValidationError
+	}{
+		{"error", report.Result.Errors},
+		{"warning", report.Result.Warnings},
+		{"notice", report.Result.Infos},
+	} {
+		for _, failure := range failures.errors {
+			index := rules.find(failure)
+
+			properties := make([]string, 0, 4)
+			if found, ok := locateFailure(failure, rules.rules[index], locator); ok {
+				properties = append(properties, "file="+escapeGitHubProperty(artifactURI(found.File)))
+				if found.Line > 0 {
+					properties = append(properties, fmt.Sprintf("line=%d", found.Line), fmt.Sprintf("col=%d", found.Column))
+				}
+			}
+			properties = append(properties, "title="+escapeGitHubProperty(rules.ids[index]))
+
+			_, err := fmt.Fprintf(
+				w, "::%s %s::%s\n", failures.command, strings.Join(properties, ","),
+				escapeGitHubData(failureMessage(failure)),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatGitHubCharts writes the workflow commands of each chart
+func formatGitHubCharts(w io.Writer, reports []*Report) error {
+	for _, report := range reports {
+		if err := formatGitHub(w, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAnnotationsReport creates a report on a chart in the working directory, with failures on values and manifests
+func newAnnotationsReport(t *testing.T) *Report {
+	dir := t.TempDir()
+	valuesFile := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("service:\n  port: 70000\n"), 0644))
+	rulesFile := filepath.Join(dir, "values.cel.yaml")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	return &Report{
+		Rules: []models.Rule{
+			{ID: "port", Expr: "values.service.port <= 65535", Desc: "port must be valid", File: rulesFile},
+			{Expr: "values.debug == false", Desc: "debug should be off", Severity: "warning", File: rulesFile},
+			{ID: "limits", Expr: "has(object.spec)", Desc: "spec must be set", Severity: "info", Match: &models.Match{}, File: rulesFile},
+		},
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{RuleID: "port", Description: "port must be valid", Expression: "values.service.port <= 65535", Path: "service.port", Value: 70000},
+			},
+			Warnings: []*models.ValidationError{
+				{Description: "debug should be off", Expression: "values.debug == false", Path: "debug", RuleIndex: 1},
+			},
+			Infos: []*models.ValidationError{
+				{RuleID: "limits", Description: "spec must be set", Expression: "has(object.spec)", Resource: "Pod/debug"},
+			},
+		},
+		ChartPath:   dir,
+		ValuesFiles: []string{valuesFile},
+	}
+}
+
+func TestFormatGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, formatGitHub(&buf, newAnnotationsReport(t)))
+
+	assert.Equal(
+		t, "::error file=values.yaml,line=2,col=3,title=port::port must be valid (path: service.port, current value: 70000)\n"+
+			"::warning file=values.cel.yaml,title=rule-2::debug should be off (path: debug, current value: <nil>)\n"+
+			"::notice file=values.cel.yaml,title=limits::spec must be set (resource: Pod/debug)\n",
+		buf.String(),
+	)
+}
+
+func TestFormatGitHub_SharedExpression(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, formatGitHub(&buf, newSharedExpressionReport()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "title=rule-1::deployments must set a security context (resource: Deployment/api)")
+	assert.Contains(t, lines[1], "title=rule-2::statefulsets should set a security context (resource: StatefulSet/db)")
+}
+
+func TestEscapeGitHub(t *testing.T) {
+	assert.Equal(t, "100%25 done%0Anext: a, b", escapeGitHubData("100% done\nnext: a, b"))
+	assert.Equal(t, "dir%2Cname%3Avalues.yaml", escapeGitHubProperty("dir,name:values.yaml"))
+}
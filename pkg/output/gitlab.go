@@ -0,0 +1,100 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// formatGitLab writes the failures as a GitLab Code Quality report, located in the values file setting the
+// failing value. Fingerprints are derived from the chart, rule, path and resource so they are stable across runs.
+func formatGitLab(w io.Writer, report *Report) error {
+	return writeGitLab(w, gitlabIssues(report))
+}
+
+// formatGitLabCharts writes the issues of all charts in one report
+func formatGitLabCharts(w io.Writer, reports []*Report) error {
+	issues := make([]gitlabIssue, 0)
+	for _, report := range reports {
+		issues = append(issues, gitlabIssues(report)...)
+	}
+	return writeGitLab(w, issues)
+}
+
+func gitlabIssues(report *Report) []gitlabIssue {
+	rules := newRuleIndex(report.Rules)
+	locator := NewValuesLocator(report.ValuesFiles)
+
+	issues := make([]gitlabIssue, 0)
+	for _, failures := range []struct {
+		severity string
+		errors   []*models.ValidationError
+	}{
+		{"major", report.Result.Errors},
+		{"minor", report.Result.Warnings},
+		{"info", report.Result.Infos},
+	} {
+		for _, failure := range failures.errors {
+			index := rules.find(failure)
+
+			// GitLab requires a location, failures without one are reported on the chart
+			location := gitlabLocation{Path: artifactURI(report.ChartPath), Lines: gitlabLines{Begin: 1}}
+			if found, ok := locateFailure(failure, rules.rules[index], locator); ok {
+				location.Path = artifactURI(found.File)
+				if found.Line > 0 {
+					location.Lines.Begin = found.Line
+				}
+			}
+
+			issues = append(
+				issues, gitlabIssue{
+					Description: failureMessage(failure),
+					CheckName:   rules.ids[index],
+					Fingerprint: gitlabFingerprint(chartName(report), rules.ids[index], failure),
+					Severity:    failures.severity,
+					Location:    location,
+				},
+			)
+		}
+	}
+
+	return issues
+}
+
+func writeGitLab(w io.Writer, issues []gitlabIssue) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(issues); err != nil {
+		return fmt.Errorf("failed to marshal output to GitLab Code Quality: %v", err)
+	}
+	return nil
+}
+
+// gitlabFingerprint identifies a failure by its chart, rule, values path and resource. Rules without an ID are
+// identified by their position, since several of them can share an expression.
+func gitlabFingerprint(chart, ruleID string, failure *models.ValidationError) string {
+	sum := sha256.Sum256([]byte(chart + "\x00" + ruleID + "\x00" + failure.Path + "\x00" + failure.Resource))
+	return hex.EncodeToString(sum[:])
+}
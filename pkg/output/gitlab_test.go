@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatGitLab(t *testing.T) {
+	report := newAnnotationsReport(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, formatGitLab(&buf, report))
+
+	var issues []gitlabIssue
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+	require.Len(t, issues, 3)
+
+	assert.Equal(t, "port must be valid (path: service.port, current value: 70000)", issues[0].Description)
+	assert.Equal(t, "port", issues[0].CheckName)
+	assert.Equal(t, "major", issues[0].Severity)
+	assert.Equal(t, gitlabLocation{Path: "values.yaml", Lines: gitlabLines{Begin: 2}}, issues[0].Location)
+
+	assert.Equal(t, "rule-2", issues[1].CheckName)
+	assert.Equal(t, "minor", issues[1].Severity)
+	assert.Equal(t, gitlabLocation{Path: "values.cel.yaml", Lines: gitlabLines{Begin: 1}}, issues[1].Location)
+
+	assert.Equal(t, "info", issues[2].Severity)
+	assert.Equal(t, "limits", issues[2].CheckName)
+
+	// Fingerprints are unique and don't depend on the current value
+	assert.Len(t, issues[0].Fingerprint, 64)
+	assert.NotEqual(t, issues[0].Fingerprint, issues[1].Fingerprint)
+	assert.NotEqual(t, issues[1].Fingerprint, issues[2].Fingerprint)
+
+	report.Result.Errors[0].Value = 80000
+	buf.Reset()
+	require.NoError(t, formatGitLab(&buf, report))
+	var rerun []gitlabIssue
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rerun))
+	assert.Equal(t, issues[0].Fingerprint, rerun[0].Fingerprint)
+}
+
+func TestFormatGitLab_SharedExpression(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, formatGitLab(&buf, newSharedExpressionReport()))
+
+	var issues []gitlabIssue
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+	require.Len(t, issues, 2)
+	assert.Equal(t, "rule-1", issues[0].CheckName)
+	assert.Equal(t, "major", issues[0].Severity)
+	assert.Equal(t, "rule-2", issues[1].CheckName)
+	assert.Equal(t, "minor", issues[1].Severity)
+	assert.NotEqual(t, issues[0].Fingerprint, issues[1].Fingerprint)
+}
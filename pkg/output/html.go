@@ -0,0 +1,353 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+const untaggedGroup = "Untagged"
+
+type htmlPage struct {
+	Title     string
+	Generated string
+	Summary   models.Summary // of all charts, shown with an overview of the charts when there are several
+	Charts    []htmlReport
+}
+
+type htmlReport struct {
+	Chart       string
+	ValuesFiles []string
+	Status      string
+	Summary     models.Summary
+	Files       []htmlFile
+	Values      string
+}
+
+type htmlFile struct {
+	Name string
+	Tags []htmlTag
+}
+
+type htmlTag struct {
+	Name  string
+	Rules []htmlRule
+}
+
+type htmlRule struct {
+	ID          string
+	Description string
+	Severity    string
+	Status      string // error, warning or info for failed rules, passed or skipped otherwise
+	Expression  string
+	Expanded    string // expression with named expressions expanded, if it differs
+	Values      []htmlValue
+	Failures    []htmlFailure
+	Reason      string
+}
+
+type htmlValue struct {
+	Path  string
+	Value string
+	Set   bool
+}
+
+type htmlFailure struct {
+	Resource string
+	Path     string
+	Value    string
+}
+
+// formatHTML writes the report as a standalone HTML page, with the rules grouped by file and tag and the merged values
+func formatHTML(w io.Writer, report *Report) error {
+	return formatHTMLCharts(w, []*Report{report})
+}
+
+// formatHTMLCharts writes the reports of several charts as a standalone HTML page, starting with an overview
+func formatHTMLCharts(w io.Writer, reports []*Report) error {
+	page := htmlPage{Title: "Validation Report", Generated: time.Now().UTC().Format(time.RFC3339)}
+	for _, report := range reports {
+		chart, err := newHTMLReport(report)
+		if err != nil {
+			return err
+		}
+		page.Summary = page.Summary.Add(chart.Summary)
+		page.Charts = append(page.Charts, chart)
+	}
+	if len(page.Charts) == 1 {
+		page.Title += ": " + page.Charts[0].Chart
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, page); err != nil {
+		return fmt.Errorf("failed to render HTML report: %v", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// newHTMLReport describes the validation of a chart
+func newHTMLReport(report *Report) (htmlReport, error) {
+	page := htmlReport{
+		Chart:   chartName(report),
+		Summary: report.Result.Summary(),
+	}
+	switch {
+	case report.Result.HasErrors():
+		page.Status = "error"
+	case len(report.Result.Warnings) > 0:
+		page.Status = "warning"
+	default:
+		page.Status = "passed"
+	}
+	for _, file := range report.ValuesFiles {
+		page.ValuesFiles = append(page.ValuesFiles, relativePath(report.ChartPath, file))
+	}
+
+	files := make(map[string]int)
+	for i, rule := range report.Rules {
+		name := relativePath(report.ChartPath, rule.File)
+		fileIndex, ok := files[name]
+		if !ok {
+			fileIndex = len(page.Files)
+			files[name] = fileIndex
+			page.Files = append(page.Files, htmlFile{Name: name})
+		}
+
+		tags := rule.Tags
+		if len(tags) == 0 {
+			tags = []string{untaggedGroup}
+		}
+		entry := newHTMLRule(rule, i, report)
+		for _, tag := range tags {
+			page.Files[fileIndex].add(tag, entry)
+		}
+	}
+	for i := range page.Files {
+		page.Files[i].sortTags()
+	}
+
+	// Keys are sorted so the values of two reports can be diffed
+	if report.Values != nil {
+		var values bytes.Buffer
+		encoder := yaml.NewEncoder(&values)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(report.Values); err != nil {
+			return htmlReport{}, fmt.Errorf("failed to marshal values to YAML: %v", err)
+		}
+		page.Values = values.String()
+	}
+
+	return page, nil
+}
+
+// newHTMLRule describes a rule with its outcome and the current value of each values path it references
+func newHTMLRule(rule models.Rule, position int, report *Report) htmlRule {
+	entry := htmlRule{
+		ID:          rule.ID,
+		Description: rule.Desc,
+		Severity:    rule.Severity,
+		Expression:  rule.Source,
+	}
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("rule-%d", position+1)
+	}
+	if entry.Severity == "" {
+		entry.Severity = validator.ErrorSeverity
+	}
+	if entry.Expression == "" {
+		entry.Expression = rule.Expr
+	} else if entry.Expression != rule.Expr {
+		entry.Expanded = rule.Expr
+	}
+
+	// Invalid expressions are reported as failures, their paths are left out
+	paths, _ := validator.ValuesPaths(rule.Expr)
+	for _, path := range paths {
+		value, found := validator.LookupValue(report.Values, path)
+		entry.Values = append(entry.Values, htmlValue{Path: path, Value: htmlValueString(value), Set: found})
+	}
+
+	for _, failures := range []struct {
+		status string
+		errors []*models.ValidationError
+	}{
+		{validator.ErrorSeverity, report.Result.Errors},
+		{validator.WarningSeverity, report.Result.Warnings},
+		{validator.InfoSeverity, report.Result.Infos},
+	} {
+		for _, failure := range ruleFailures(rule, position, failures.errors) {
+			if entry.Status == "" {
+				entry.Status = failures.status
+			}
+			entry.Failures = append(
+				entry.Failures, htmlFailure{
+					Resource: failure.Resource,
+					Path:     failure.Path,
+					Value:    htmlValueString(failure.Value),
+				},
+			)
+		}
+	}
+
+	switch {
+	case entry.Status != "":
+	case rulePassed(rule, position, report.Result.Passed):
+		entry.Status = "passed"
+	default:
+		entry.Status = "skipped"
+		entry.Reason = ruleSkipReason(rule, position, report.Result.Skipped)
+	}
+	return entry
+}
+
+func (f *htmlFile) add(tag string, rule htmlRule) {
+	for i := range f.Tags {
+		if f.Tags[i].Name == tag {
+			f.Tags[i].Rules = append(f.Tags[i].Rules, rule)
+			return
+		}
+	}
+	f.Tags = append(f.Tags, htmlTag{Name: tag, Rules: []htmlRule{rule}})
+}
+
+// sortTags sorts the tags by name, with untagged rules last
+func (f *htmlFile) sortTags() {
+	sort.SliceStable(
+		f.Tags, func(i, j int) bool {
+			if f.Tags[i].Name == untaggedGroup || f.Tags[j].Name == untaggedGroup {
+				return f.Tags[j].Name == untaggedGroup && f.Tags[i].Name != untaggedGroup
+			}
+			return f.Tags[i].Name < f.Tags[j].Name
+		},
+	)
+}
+
+// relativePath returns the path of a file relative to the chart, or as is if it is outside of it
+func relativePath(chartPath, file string) string {
+	if rel, err := filepath.Rel(chartPath, file); err == nil && file != "" && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// htmlValueString formats a value as JSON, so strings can be told apart from other types
+func htmlValueString(value any) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(content)
+}
+
+var htmlReportTemplate = template.Must(
+	template.New("report").Parse(
+		`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code, pre { font-size: 0.9em; }
+pre { background: #f7f7f7; border: 1px solid #ddd; padding: 1em; overflow-x: auto; }
+.summary { display: flex; flex-wrap: wrap; gap: 1em; margin: 1em 0; }
+.summary div { border: 1px solid #ccc; border-radius: 4px; padding: 0.6em 1em; min-width: 6em; text-align: center; }
+.summary strong { display: block; font-size: 1.6em; }
+details { border: 1px solid #ddd; border-left-width: 6px; border-radius: 4px; margin: 0.4em 0; padding: 0.4em 0.8em; }
+summary { cursor: pointer; }
+.badge { display: inline-block; min-width: 5em; font-weight: bold; text-transform: uppercase; font-size: 0.8em; }
+.error { border-left-color: #c62828; } .error .badge, h1.error { color: #c62828; }
+.warning { border-left-color: #ef6c00; } .warning .badge, h1.warning { color: #ef6c00; }
+.info { border-left-color: #1565c0; } .info .badge { color: #1565c0; }
+.passed { border-left-color: #2e7d32; } .passed .badge, h1.passed { color: #2e7d32; }
+.skipped { border-left-color: #9e9e9e; } .skipped .badge { color: #757575; }
+.unset { color: #757575; font-style: italic; }
+</style>
+</head>
+<body>
+{{- if gt (len .Charts) 1 }}
+<h1>{{ .Title }}</h1>
+<p>Generated: {{ .Generated }}</p>
+{{ template "summary" .Summary }}
+<table>
+<tr><th>Chart</th><th>Status</th><th>Rules</th><th>Passed</th><th>Failed</th><th>Skipped</th><th>Errors</th><th>Warnings</th><th>Infos</th></tr>
+{{- range $i, $chart := .Charts }}
+<tr class="{{ .Status }}"><td><a href="#chart-{{ $i }}">{{ .Chart }}</a></td><td><span class="badge">{{ .Status }}</span></td><td>{{ .Summary.Rules }}</td><td>{{ .Summary.Passed }}</td><td>{{ .Summary.Failed }}</td><td>{{ .Summary.Skipped }}</td><td>{{ .Summary.Errors }}</td><td>{{ .Summary.Warnings }}</td><td>{{ .Summary.Infos }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- range $i, $chart := .Charts }}
+<section id="chart-{{ $i }}">
+<h1 class="{{ .Status }}">Validation Report: {{ .Chart }}</h1>
+<p>Values files: {{ range $i, $file := .ValuesFiles }}{{ if $i }}, {{ end }}<code>{{ $file }}</code>{{ end }}{{ if eq (len $.Charts) 1 }}<br>Generated: {{ $.Generated }}{{ end }}</p>
+{{ template "summary" .Summary }}
+{{- range .Files }}
+<h2>{{ .Name }}</h2>
+{{- range .Tags }}
+<h3>{{ .Name }}</h3>
+{{- range .Rules }}
+<details class="{{ .Status }}"{{ if or (eq .Status "error") (eq .Status "warning") }} open{{ end }}>
+<summary><span class="badge">{{ .Status }}</span> <code>{{ .ID }}</code> {{ .Description }} ({{ .Severity }})</summary>
+<p>Expression: <code>{{ .Expression }}</code></p>
+{{- if .Expanded }}
+<p>Expanded expression: <code>{{ .Expanded }}</code></p>
+{{- end }}
+{{- if .Values }}
+<table>
+<tr><th>Values path</th><th>Current value</th></tr>
+{{- range .Values }}
+<tr><td><code>{{ .Path }}</code></td><td>{{ if .Set }}<code>{{ .Value }}</code>{{ else }}<span class="unset">not set</span>{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- if .Failures }}
+<table>
+<tr><th>Resource</th><th>Failing path</th><th>Value</th></tr>
+{{- range .Failures }}
+<tr><td>{{ if .Resource }}{{ .Resource }}{{ else }}-{{ end }}</td><td>{{ if .Path }}<code>{{ .Path }}</code>{{ else }}-{{ end }}</td><td><code>{{ .Value }}</code></td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- if .Reason }}
+<p>Skipped: {{ .Reason }}</p>
+{{- end }}
+</details>
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Values }}
+<h2>Merged values</h2>
+<pre>{{ .Values }}</pre>
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+{{ define "summary" -}}
+<div class="summary">
+<div><strong>{{ .Rules }}</strong>rules</div>
+<div class="passed"><strong>{{ .Passed }}</strong>passed</div>
+<div class="error"><strong>{{ .Failed }}</strong>failed</div>
+<div class="skipped"><strong>{{ .Skipped }}</strong>skipped</div>
+<div class="error"><strong>{{ .Errors }}</strong>errors</div>
+<div class="warning"><strong>{{ .Warnings }}</strong>warnings</div>
+<div class="info"><strong>{{ .Infos }}</strong>infos</div>
+<div><strong>{{ printf "%.1f" .DurationMs }}</strong>ms</div>
+</div>
+{{- end }}`,
+	),
+)
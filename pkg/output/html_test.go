@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatHTML(t *testing.T) {
+	report := newAnnotationsReport(t)
+	rulesFile := filepath.Join(report.ChartPath, "values.cel.yaml")
+	report.Rules[0].Source = "${port} <= 65535"
+	report.Rules[0].Tags = []string{"network"}
+	report.Rules = append(
+		report.Rules,
+		models.Rule{ID: "replicas", Expr: "values.replicas > 0", Desc: "replicas must be positive", File: rulesFile},
+		models.Rule{ID: "ingress", Expr: "has(object.spec.tls)", Desc: "ingresses <b>must</b> use TLS", Match: &models.Match{}, File: rulesFile},
+	)
+	report.Result.Passed = []*models.PassedRule{
+		{RuleID: "replicas", Description: "replicas must be positive", Expression: "values.replicas > 0"},
+	}
+	report.Result.Skipped = []*models.SkippedRule{
+		{RuleID: "ingress", Description: "ingresses <b>must</b> use TLS", Expression: "has(object.spec.tls)", Reason: "no rendered manifests"},
+	}
+	report.Values = map[string]any{"service": map[string]any{"port": 70000}, "replicas": 2}
+
+	var buf bytes.Buffer
+	require.NoError(t, formatHTML(&buf, report))
+	page := buf.String()
+
+	assert.Contains(t, page, `<h1 class="error">Validation Report: `+filepath.Base(report.ChartPath)+`</h1>`)
+	assert.Contains(t, page, "<code>values.yaml</code>")
+	assert.Contains(t, page, "<div><strong>5</strong>rules</div>")
+	assert.Contains(t, page, `<div class="passed"><strong>1</strong>passed</div>`)
+	assert.Contains(t, page, `<div class="skipped"><strong>1</strong>skipped</div>`)
+
+	// Rules are grouped by file, then by tag with untagged rules last
+	assert.Less(t, strings.Index(page, "<h3>network</h3>"), strings.Index(page, "<h3>Untagged</h3>"))
+	assert.Contains(t, page, "<h2>values.cel.yaml</h2>")
+
+	assert.Contains(
+		t, page, `<details class="error" open>
+<summary><span class="badge">error</span> <code>port</code> port must be valid (error)</summary>
+<p>Expression: <code>${port} &lt;= 65535</code></p>
+<p>Expanded expression: <code>values.service.port &lt;= 65535</code></p>`,
+	)
+	assert.Contains(t, page, "<tr><td><code>values.service.port</code></td><td><code>70000</code></td></tr>")
+	assert.Contains(t, page, "<tr><td>-</td><td><code>service.port</code></td><td><code>70000</code></td></tr>")
+	assert.Contains(t, page, `<summary><span class="badge">warning</span> <code>rule-2</code> debug should be off (warning)</summary>`)
+	assert.Contains(t, page, `<span class="unset">not set</span>`)
+	assert.Contains(t, page, `<details class="passed">`)
+	assert.Contains(t, page, "ingresses &lt;b&gt;must&lt;/b&gt; use TLS")
+	assert.Contains(t, page, "<p>Skipped: no rendered manifests</p>")
+
+	assert.Contains(t, page, "<h2>Merged values</h2>\n<pre>replicas: 2\nservice:\n  port: 70000\n</pre>")
+	assert.NotContains(t, page, "<script")
+	assert.NotContains(t, page, "<link")
+}
@@ -0,0 +1,172 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/junit"
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// formatJUnit writes the report as JUnit XML with one test suite per rules file and one test case per enabled rule.
+// Errors are failures, warnings are skipped test cases and infos are recorded as properties, rules that were not
+// evaluated, e.g. rules on rendered manifests when none are given, are skipped too.
+func formatJUnit(w io.Writer, report *Report) error {
+	suites := &junit.TestSuites{Name: toolName}
+	for _, suite := range junitSuites(report, "") {
+		suites.Add(suite)
+	}
+	return suites.Write(w)
+}
+
+// formatJUnitCharts writes the test suites of all charts in one report, prefixing their names with the chart
+func formatJUnitCharts(w io.Writer, reports []*Report) error {
+	suites := &junit.TestSuites{Name: toolName}
+	for _, report := range reports {
+		for _, suite := range junitSuites(report, chartName(report)+"/") {
+			suites.Add(suite)
+		}
+	}
+	return suites.Write(w)
+}
+
+// junitSuites returns a test suite per rules file of the report, named after the file prefixed with prefix
+func junitSuites(report *Report, prefix string) []junit.TestSuite {
+	index := make(map[string]int)
+	junitSuites := make([]junit.TestSuite, 0)
+
+	for position, rule := range report.Rules {
+		if rule.IsDisabled() {
+			continue
+		}
+
+		name := "rules"
+		if rule.File != "" {
+			name = rule.File
+			if rel, err := filepath.Rel(report.ChartPath, rule.File); err == nil && report.ChartPath != "" {
+				name = rel
+			}
+		}
+		name = prefix + name
+		i, ok := index[name]
+		if !ok {
+			i = len(junitSuites)
+			index[name] = i
+			junitSuites = append(junitSuites, junit.TestSuite{Name: name})
+		}
+		suite := &junitSuites[i]
+
+		testCase := junit.TestCase{Name: ruleCaseName(rule, position), Classname: name}
+		errors := ruleFailures(rule, position, report.Result.Errors)
+		warnings := ruleFailures(rule, position, report.Result.Warnings)
+		infos := ruleFailures(rule, position, report.Result.Infos)
+
+		switch {
+		case len(errors) > 0:
+			suite.Failures++
+			testCase.Failure = &junit.Failure{
+				Message: rule.Desc,
+				Type:    "error",
+				Body:    failureBodies(errors),
+			}
+		case len(warnings) > 0:
+			suite.Skipped++
+			testCase.Skipped = &junit.Skipped{Message: "warning: " + rule.Desc}
+			testCase.Properties = failureProperties("warning", warnings)
+		case len(infos) > 0:
+			testCase.Properties = failureProperties("info", infos)
+		case !rulePassed(rule, position, report.Result.Passed):
+			suite.Skipped++
+			testCase.Skipped = &junit.Skipped{Message: ruleSkipReason(rule, position, report.Result.Skipped)}
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	return junitSuites
+}
+
+// ruleCaseName names the test case of a rule after its ID and description
+func ruleCaseName(rule models.Rule, position int) string {
+	id := rule.ID
+	if id == "" {
+		id = fmt.Sprintf("rule-%d", position+1)
+	}
+	return fmt.Sprintf("%s: %s", id, rule.Desc)
+}
+
+// ruleFailures returns the failures of the rule at position in the report rules
+func ruleFailures(rule models.Rule, position int, failures []*models.ValidationError) []*models.ValidationError {
+	matched := make([]*models.ValidationError, 0)
+	for _, failure := range failures {
+		if matchesRule(rule, position, failure.RuleID, failure.RuleIndex) {
+			matched = append(matched, failure)
+		}
+	}
+	return matched
+}
+
+func rulePassed(rule models.Rule, position int, passed []*models.PassedRule) bool {
+	for _, pass := range passed {
+		if matchesRule(rule, position, pass.RuleID, pass.RuleIndex) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleSkipReason returns why a rule that did not run was skipped
+func ruleSkipReason(rule models.Rule, position int, skipped []*models.SkippedRule) string {
+	for _, skip := range skipped {
+		if matchesRule(rule, position, skip.RuleID, skip.RuleIndex) {
+			return skip.Reason
+		}
+	}
+	return "not evaluated"
+}
+
+// matchesRule reports whether a result belongs to the rule at position in the report rules, by ID or by position
+// for rules without one, since several rules without ID can share an expression
+func matchesRule(rule models.Rule, position int, ruleID string, ruleIndex int) bool {
+	if ruleID != "" {
+		return rule.ID == ruleID
+	}
+	return rule.ID == "" && position == ruleIndex
+}
+
+// failureBodies describes each failure with its expression, resource, path and value
+func failureBodies(failures []*models.ValidationError) string {
+	bodies := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		var body strings.Builder
+		body.WriteString(fmt.Sprintf("Expression: %s\n", failure.Expression))
+		if failure.Resource != "" {
+			body.WriteString(fmt.Sprintf("Resource: %s\n", failure.Resource))
+		}
+		if failure.Path != "" {
+			body.WriteString(fmt.Sprintf("Path: %s\n", failure.Path))
+		}
+		body.WriteString(fmt.Sprintf("Value: %v", failure.Value))
+		bodies = append(bodies, body.String())
+	}
+	return strings.Join(bodies, "\n\n")
+}
+
+// failureProperties records the severity and the details of each failure as properties
+func failureProperties(severity string, failures []*models.ValidationError) *junit.Properties {
+	properties := &junit.Properties{Property: []junit.Property{{Name: "severity", Value: severity}}}
+	for _, failure := range failures {
+		properties.Property = append(properties.Property, junit.Property{Name: "expression", Value: failure.Expression})
+		if failure.Resource != "" {
+			properties.Property = append(properties.Property, junit.Property{Name: "resource", Value: failure.Resource})
+		}
+		if failure.Path != "" {
+			properties.Property = append(properties.Property, junit.Property{Name: "path", Value: failure.Path})
+		}
+		properties.Property = append(properties.Property, junit.Property{Name: "value", Value: fmt.Sprintf("%v", failure.Value)})
+	}
+	return properties
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/junit"
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatJUnit(t *testing.T) {
+	disabled := true
+	report := &Report{
+		ChartPath: "/chart",
+		Rules: []models.Rule{
+			{ID: "port", Expr: "values.service.port <= 65535", Desc: "port must be valid", File: "/chart/values.cel.yaml"},
+			{Expr: "values.debug == false", Desc: "debug should be off", Severity: "warning", File: "/chart/values.cel.yaml"},
+			{ID: "replicas", Expr: "values.replicas > 0", Desc: "replicas must be positive", File: "/chart/values.cel.yaml"},
+			{ID: "disabled", Expr: "false", Desc: "disabled", Disabled: &disabled, File: "/chart/values.cel.yaml"},
+			{ID: "limits", Expr: "has(object.spec)", Desc: "spec should be set", Severity: "info", Match: &models.Match{}, File: "/chart/manifests.cel.yaml"},
+			{ID: "labels", Expr: "has(object.metadata.labels)", Desc: "labels must be set", Match: &models.Match{}, File: "/chart/manifests.cel.yaml"},
+		},
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{RuleID: "port", Description: "port must be valid", Expression: "values.service.port <= 65535", Path: "service.port", Value: 70000},
+			},
+			Warnings: []*models.ValidationError{
+				{Description: "debug should be off", Expression: "values.debug == false", Path: "debug", Value: true, RuleIndex: 1},
+			},
+			Infos: []*models.ValidationError{
+				{RuleID: "limits", Description: "spec should be set", Expression: "has(object.spec)", Resource: "ConfigMap/config"},
+			},
+			Passed: []*models.PassedRule{
+				{RuleID: "replicas", Description: "replicas must be positive", Expression: "values.replicas > 0"},
+			},
+			Skipped: []*models.SkippedRule{
+				{RuleID: "labels", Description: "labels must be set", Expression: "has(object.metadata.labels)", Reason: "no rendered manifests"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, formatJUnit(&buf, report))
+
+	var suites junit.TestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 2, suites.Skipped)
+	require.Len(t, suites.Suites, 2)
+
+	values := suites.Suites[0]
+	assert.Equal(t, "values.cel.yaml", values.Name)
+	require.Len(t, values.Cases, 3)
+
+	assert.Equal(t, "port: port must be valid", values.Cases[0].Name)
+	assert.Equal(t, "values.cel.yaml", values.Cases[0].Classname)
+	require.NotNil(t, values.Cases[0].Failure)
+	assert.Equal(t, "port must be valid", values.Cases[0].Failure.Message)
+	assert.Equal(t, "Expression: values.service.port <= 65535\nPath: service.port\nValue: 70000", values.Cases[0].Failure.Body)
+
+	assert.Equal(t, "rule-2: debug should be off", values.Cases[1].Name)
+	require.NotNil(t, values.Cases[1].Skipped)
+	assert.Equal(t, "warning: debug should be off", values.Cases[1].Skipped.Message)
+	assert.Equal(
+		t, []junit.Property{
+			{Name: "severity", Value: "warning"},
+			{Name: "expression", Value: "values.debug == false"},
+			{Name: "path", Value: "debug"},
+			{Name: "value", Value: "true"},
+		}, values.Cases[1].Properties.Property,
+	)
+
+	assert.Equal(t, "replicas: replicas must be positive", values.Cases[2].Name)
+	assert.Nil(t, values.Cases[2].Failure)
+	assert.Nil(t, values.Cases[2].Skipped)
+
+	manifests := suites.Suites[1]
+	assert.Equal(t, "manifests.cel.yaml", manifests.Name)
+	require.Len(t, manifests.Cases, 2)
+	assert.Nil(t, manifests.Cases[0].Skipped)
+	assert.Contains(t, manifests.Cases[0].Properties.Property, junit.Property{Name: "resource", Value: "ConfigMap/config"})
+	require.NotNil(t, manifests.Cases[1].Skipped)
+	assert.Equal(t, "no rendered manifests", manifests.Cases[1].Skipped.Message)
+}
+
+// newSharedExpressionReport returns a report of rules without ID sharing an expression on different kinds
+func newSharedExpressionReport() *Report {
+	expr := "has(object.spec.template.spec.securityContext)"
+	return &Report{
+		ChartPath: "/chart",
+		Rules: []models.Rule{
+			{Expr: expr, Desc: "deployments must set a security context", Match: &models.Match{Kinds: []string{"Deployment"}}, File: "/chart/values.cel.yaml"},
+			{Expr: expr, Desc: "statefulsets should set a security context", Severity: "warning", Match: &models.Match{Kinds: []string{"StatefulSet"}}, File: "/chart/values.cel.yaml"},
+			{Expr: expr, Desc: "daemonsets should set a security context", Severity: "info", Match: &models.Match{Kinds: []string{"DaemonSet"}}, File: "/chart/values.cel.yaml"},
+		},
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{Description: "deployments must set a security context", Expression: expr, Resource: "Deployment/api", RuleIndex: 0},
+			},
+			Warnings: []*models.ValidationError{
+				{Description: "statefulsets should set a security context", Expression: expr, Resource: "StatefulSet/db", RuleIndex: 1},
+			},
+			Skipped: []*models.SkippedRule{
+				{Description: "daemonsets should set a security context", Expression: expr, Reason: "no matching manifests", RuleIndex: 2},
+			},
+		},
+	}
+}
+
+func TestFormatJUnit_SharedExpression(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, formatJUnit(&buf, newSharedExpressionReport()))
+
+	var suites junit.TestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+	cases := suites.Suites[0].Cases
+	require.Len(t, cases, 3)
+
+	assert.Equal(t, "rule-1: deployments must set a security context", cases[0].Name)
+	require.NotNil(t, cases[0].Failure)
+	assert.Contains(t, cases[0].Failure.Body, "Resource: Deployment/api")
+	assert.NotContains(t, cases[0].Failure.Body, "StatefulSet/db")
+	assert.Nil(t, cases[0].Skipped)
+
+	assert.Nil(t, cases[1].Failure)
+	require.NotNil(t, cases[1].Skipped)
+	assert.Equal(t, "warning: statefulsets should set a security context", cases[1].Skipped.Message)
+
+	assert.Nil(t, cases[2].Failure)
+	require.NotNil(t, cases[2].Skipped)
+	assert.Equal(t, "no matching manifests", cases[2].Skipped.Message)
+	assert.Equal(t, 1, suites.Failures)
+}
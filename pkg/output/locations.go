@@ -0,0 +1,100 @@
+package output
+
+import (
+	"os"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Location is a position in a values file
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// ValuesLocator finds the position of values paths in values files, parsing each file once
+type ValuesLocator struct {
+	files []string
+	nodes map[string]*yaml.Node
+}
+
+// NewValuesLocator creates a locator for values files given in order of precedence
+func NewValuesLocator(files []string) *ValuesLocator {
+	return &ValuesLocator{files: files, nodes: make(map[string]*yaml.Node)}
+}
+
+// Locate returns the position of the key setting a values path, e.g. service.port, in the last values file
+// setting it, falling back to the closest parent set in any file
+func (l *ValuesLocator) Locate(path string) (Location, bool) {
+	path = strings.TrimPrefix(path, "values.")
+	if path == "" {
+		return Location{}, false
+	}
+	fields := strings.Split(path, ".")
+
+	for len(fields) > 0 {
+		for i := len(l.files) - 1; i >= 0; i-- {
+			if key := lookupKey(l.root(l.files[i]), fields); key != nil {
+				return Location{File: l.files[i], Line: key.Line, Column: key.Column}, true
+			}
+		}
+		fields = fields[:len(fields)-1]
+	}
+
+	return Location{}, false
+}
+
+// root returns the top-level mapping of a values file, or nil if it can't be read
+func (l *ValuesLocator) root(file string) *yaml.Node {
+	if node, ok := l.nodes[file]; ok {
+		return node
+	}
+
+	var root *yaml.Node
+	if content, err := os.ReadFile(file); err == nil {
+		var document yaml.Node
+		if err := yaml.Unmarshal(content, &document); err == nil && len(document.Content) > 0 {
+			root = document.Content[0]
+		}
+	}
+	l.nodes[file] = root
+	return root
+}
+
+// lookupKey returns the key node of the last field of the path
+func lookupKey(node *yaml.Node, fields []string) *yaml.Node {
+	var key *yaml.Node
+	for _, field := range fields {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		mapping := node
+		key, node = nil, nil
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == field {
+				key, node = mapping.Content[i], mapping.Content[i+1]
+			}
+		}
+		if key == nil {
+			return nil
+		}
+	}
+	return key
+}
+
+// locateFailure returns the position in the values files setting the failing value of a failure on values,
+// falling back to the rules file of the rule with no line
+func locateFailure(failure *models.ValidationError, rule models.Rule, locator *ValuesLocator) (Location, bool) {
+	if failure.Resource == "" {
+		if found, ok := locator.Locate(failure.Path); ok {
+			return found, true
+		}
+	}
+	if rule.File != "" {
+		return Location{File: rule.File}, true
+	}
+	return Location{}, false
+}
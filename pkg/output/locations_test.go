@@ -0,0 +1,58 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuesLocator_Locate(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "values.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	require.NoError(
+		t, os.WriteFile(
+			base, []byte(`replicas: 1
+service:
+  type: ClusterIP
+  port: 80
+`), 0644,
+		),
+	)
+	require.NoError(
+		t, os.WriteFile(
+			prod, []byte(`# production overrides
+service:
+    port: 8080
+`), 0644,
+		),
+	)
+
+	tests := []struct {
+		name   string
+		path   string
+		want   Location
+		wantOk bool
+	}{
+		{name: "set in the base file", path: "service.type", want: Location{File: base, Line: 3, Column: 3}, wantOk: true},
+		{name: "overridden in a later file", path: "values.service.port", want: Location{File: prod, Line: 3, Column: 5}, wantOk: true},
+		{name: "top-level key", path: "replicas", want: Location{File: base, Line: 1, Column: 1}, wantOk: true},
+		{name: "unset key falls back to its parent", path: "service.nodePort", want: Location{File: prod, Line: 2, Column: 1}, wantOk: true},
+		{name: "unknown path", path: "ingress.enabled"},
+		{name: "empty path", path: ""},
+	}
+
+	locator := NewValuesLocator([]string{base, prod, filepath.Join(dir, "missing.yaml")})
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, ok := locator.Locate(tt.path)
+				assert.Equal(t, tt.wantOk, ok)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
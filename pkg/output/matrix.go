@@ -0,0 +1,55 @@
+package output
+
+import (
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// matrixOutput combines the outputs of the environments of a matrix, keyed by environment, with the outcome of each
+// rule side by side
+func matrixOutput(reports []*Report) models.MatrixOutput {
+	combined := models.MatrixOutput{
+		Rules:        make([]models.MatrixRule, 0),
+		Environments: make(map[string]models.ValidationOutput),
+	}
+	for _, report := range reports {
+		environment := validationOutput(report)
+		combined.HasErrors = combined.HasErrors || environment.HasErrors
+		combined.HasWarnings = combined.HasWarnings || environment.HasWarnings
+		combined.HasInfos = combined.HasInfos || environment.HasInfos
+		combined.Summary = combined.Summary.Add(environment.Summary)
+		combined.Environments[report.Environment] = environment
+	}
+
+	if len(reports) == 0 {
+		return combined
+	}
+	for position, rule := range reports[0].Rules {
+		matrixRule := models.MatrixRule{
+			ID:          rule.ID,
+			Description: rule.Desc,
+			Expression:  rule.Expr,
+			Results:     make(map[string]string),
+		}
+		for _, report := range reports {
+			matrixRule.Results[report.Environment] = ruleStatus(rule, position, report.Result)
+		}
+		combined.Rules = append(combined.Rules, matrixRule)
+	}
+	return combined
+}
+
+// ruleStatus returns the severity of the failures of the rule at position, or whether it passed or was skipped
+func ruleStatus(rule models.Rule, position int, result *models.ValidationResult) string {
+	switch {
+	case len(ruleFailures(rule, position, result.Errors)) > 0:
+		return "error"
+	case len(ruleFailures(rule, position, result.Warnings)) > 0:
+		return "warning"
+	case len(ruleFailures(rule, position, result.Infos)) > 0:
+		return "info"
+	case rulePassed(rule, position, result.Passed):
+		return "passed"
+	default:
+		return "skipped"
+	}
+}
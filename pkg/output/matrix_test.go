@@ -0,0 +1,79 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMatrixReports creates the reports of a dev environment with a warning and a prod environment with an error
+func newMatrixReports() []*Report {
+	rules := []models.Rule{
+		{ID: "port", Expr: "values.service.port <= 65535", Desc: "port must be valid"},
+		{Expr: "values.debug == false", Desc: "debug should be off", Severity: "warning"},
+		{ID: "limits", Expr: "has(object.spec)", Desc: "spec must be set", Match: &models.Match{}},
+	}
+	port := rules[0]
+	debug := rules[1]
+	limits := &models.SkippedRule{RuleID: "limits", Description: "spec must be set", Expression: "has(object.spec)", Reason: "no rendered manifests"}
+
+	return []*Report{
+		{
+			Environment: "dev",
+			Rules:       rules,
+			Result: &models.ValidationResult{
+				Warnings: []*models.ValidationError{
+					{Description: debug.Desc, Expression: debug.Expr, Path: "debug", Value: true, RuleIndex: 1},
+				},
+				Passed:  []*models.PassedRule{{RuleID: port.ID, Description: port.Desc, Expression: port.Expr}},
+				Skipped: []*models.SkippedRule{limits},
+			},
+		},
+		{
+			Environment: "prod",
+			Rules:       rules,
+			Failed:      true,
+			Result: &models.ValidationResult{
+				Errors: []*models.ValidationError{
+					{RuleID: port.ID, Description: port.Desc, Expression: port.Expr, Path: "service.port", Value: 70000},
+				},
+				Passed:  []*models.PassedRule{{Description: debug.Desc, Expression: debug.Expr, RuleIndex: 1}},
+				Skipped: []*models.SkippedRule{limits},
+			},
+		},
+	}
+}
+
+func TestMatrixOutput(t *testing.T) {
+	output := matrixOutput(newMatrixReports())
+
+	assert.True(t, output.HasErrors)
+	assert.True(t, output.HasWarnings)
+	assert.False(t, output.HasInfos)
+	assert.Equal(t, models.Summary{Rules: 6, Passed: 2, Failed: 2, Skipped: 2, Errors: 1, Warnings: 1}, output.Summary)
+	assert.Equal(
+		t, []models.MatrixRule{
+			{
+				ID:          "port",
+				Description: "port must be valid",
+				Expression:  "values.service.port <= 65535",
+				Results:     map[string]string{"dev": "passed", "prod": "error"},
+			},
+			{
+				Description: "debug should be off",
+				Expression:  "values.debug == false",
+				Results:     map[string]string{"dev": "warning", "prod": "passed"},
+			},
+			{
+				ID:          "limits",
+				Description: "spec must be set",
+				Expression:  "has(object.spec)",
+				Results:     map[string]string{"dev": "skipped", "prod": "skipped"},
+			},
+		}, output.Rules,
+	)
+	assert.Len(t, output.Environments, 2)
+	assert.False(t, output.Environments["dev"].HasErrors)
+	assert.True(t, output.Environments["prod"].HasErrors)
+}
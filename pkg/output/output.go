@@ -0,0 +1,176 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Report is a validation result with the context formatters need to describe it
+type Report struct {
+	Result      *models.ValidationResult
+	Rules       []models.Rule  // merged rules, including the ones that passed
+	ChartPath   string         // absolute path of the chart
+	ValuesFiles []string       // absolute paths of the values files, in order of precedence
+	Values      map[string]any // merged values
+	Failed      bool           // the result fails validation with the --fail-on threshold
+	Environment string         // name of the environment in a matrix validation
+}
+
+// Formatter writes a validation report in an output format
+type Formatter interface {
+	Format(w io.Writer, report *Report) error
+}
+
+// FormatterFunc adapts a function to the Formatter interface
+type FormatterFunc func(w io.Writer, report *Report) error
+
+func (f FormatterFunc) Format(w io.Writer, report *Report) error {
+	return f(w, report)
+}
+
+// ChartsFormatter is implemented by formatters that can combine the reports of several charts in one output
+type ChartsFormatter interface {
+	FormatCharts(w io.Writer, reports []*Report) error
+}
+
+// chartsFormatter adapts a function formatting a chart and a function formatting several charts to both interfaces
+type chartsFormatter struct {
+	FormatterFunc
+	formatCharts func(w io.Writer, reports []*Report) error
+}
+
+func (f chartsFormatter) FormatCharts(w io.Writer, reports []*Report) error {
+	return f.formatCharts(w, reports)
+}
+
+// MatrixFormatter is implemented by formatters that can show the reports of the environments of a chart side by side
+type MatrixFormatter interface {
+	FormatMatrix(w io.Writer, reports []*Report) error
+}
+
+// matrixFormatter adds a function formatting the environments of a matrix to a chartsFormatter
+type matrixFormatter struct {
+	chartsFormatter
+	formatMatrix func(w io.Writer, reports []*Report) error
+}
+
+func (f matrixFormatter) FormatMatrix(w io.Writer, reports []*Report) error {
+	return f.formatMatrix(w, reports)
+}
+
+var formatters = make(map[string]Formatter)
+
+// Register makes a formatter available under the name of its output format
+func Register(name string, formatter Formatter) {
+	if _, ok := formatters[name]; ok {
+		panic(fmt.Sprintf("output format '%s' is already registered", name))
+	}
+	formatters[name] = formatter
+}
+
+// Lookup returns the formatter registered for an output format
+func Lookup(name string) (Formatter, bool) {
+	formatter, ok := formatters[name]
+	return formatter, ok
+}
+
+// Names returns the sorted names of the registered output formats
+func Names() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("json", matrixFormatter{chartsFormatter{formatJSON, formatJSONCharts}, formatJSONMatrix})
+	Register("yaml", matrixFormatter{chartsFormatter{formatYAML, formatYAMLCharts}, formatYAMLMatrix})
+	Register("sarif", chartsFormatter{formatSARIF, formatSARIFCharts})
+	Register("junit", chartsFormatter{formatJUnit, formatJUnitCharts})
+	Register("github", chartsFormatter{formatGitHub, formatGitHubCharts})
+	Register("gitlab", chartsFormatter{formatGitLab, formatGitLabCharts})
+	Register("html", chartsFormatter{formatHTML, formatHTMLCharts})
+}
+
+func validationOutput(report *Report) models.ValidationOutput {
+	return models.ValidationOutput{
+		HasErrors:   report.Result.HasErrors(),
+		HasWarnings: len(report.Result.Warnings) > 0,
+		HasInfos:    len(report.Result.Infos) > 0,
+		Summary:     report.Result.Summary(),
+		Result:      report.Result,
+	}
+}
+
+// chartsOutput combines the outputs of several charts, keyed by chart
+func chartsOutput(reports []*Report) models.ChartsOutput {
+	combined := models.ChartsOutput{Charts: make(map[string]models.ValidationOutput)}
+	for _, report := range reports {
+		chart := validationOutput(report)
+		combined.HasErrors = combined.HasErrors || chart.HasErrors
+		combined.HasWarnings = combined.HasWarnings || chart.HasWarnings
+		combined.HasInfos = combined.HasInfos || chart.HasInfos
+		combined.Summary = combined.Summary.Add(chart.Summary)
+		combined.Charts[chartName(report)] = chart
+	}
+	return combined
+}
+
+func formatJSON(w io.Writer, report *Report) error {
+	return writeJSON(w, validationOutput(report))
+}
+
+func formatJSONCharts(w io.Writer, reports []*Report) error {
+	return writeJSON(w, chartsOutput(reports))
+}
+
+func formatJSONMatrix(w io.Writer, reports []*Report) error {
+	return writeJSON(w, matrixOutput(reports))
+}
+
+func formatYAML(w io.Writer, report *Report) error {
+	return writeYAML(w, validationOutput(report))
+}
+
+func formatYAMLCharts(w io.Writer, reports []*Report) error {
+	return writeYAML(w, chartsOutput(reports))
+}
+
+func formatYAMLMatrix(w io.Writer, reports []*Report) error {
+	return writeYAML(w, matrixOutput(reports))
+}
+
+func writeJSON(w io.Writer, output any) error {
+	content, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output to JSON: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(content))
+	return err
+}
+
+func writeYAML(w io.Writer, output any) error {
+	content, err := yaml.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal output to YAML: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(content))
+	return err
+}
+
+// chartName names the chart of a report by its path relative to the working directory
+func chartName(report *Report) string {
+	name := artifactURI(report.ChartPath)
+	if name == "." {
+		return filepath.Base(report.ChartPath)
+	}
+	return name
+}
@@ -0,0 +1,212 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{"github", "gitlab", "html", "json", "junit", "sarif", "yaml"}, Names())
+
+	_, ok := Lookup("sarif")
+	assert.True(t, ok)
+	_, ok = Lookup("unknown")
+	assert.False(t, ok)
+
+	assert.Panics(
+		t, func() {
+			Register("json", FormatterFunc(func(io.Writer, *Report) error { return nil }))
+		},
+	)
+}
+
+func TestFormatSARIF(t *testing.T) {
+	disabled := true
+	dir := t.TempDir()
+	valuesFile := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("service:\n  port: 70000\n"), 0644))
+	rulesFile := filepath.Join(dir, "values.cel.yaml")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	report := &Report{
+		Rules: []models.Rule{
+			{ID: "port", Expr: "values.service.port <= 65535", Desc: "port must be valid", File: rulesFile},
+			{Expr: "values.debug == false", Desc: "debug should be off", Severity: "warning", File: rulesFile},
+			{ID: "disabled", Expr: "false", Desc: "disabled", Disabled: &disabled},
+			{ID: "limits", Expr: "has(object.spec)", Desc: "spec must be set", Severity: "info", Match: &models.Match{}, File: rulesFile},
+		},
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{RuleID: "port", Description: "port must be valid", Expression: "values.service.port <= 65535", Path: "service.port", Value: 70000},
+			},
+			Warnings: []*models.ValidationError{
+				{Description: "debug should be off", Expression: "values.debug == false", Path: "debug", RuleIndex: 1},
+			},
+			Infos: []*models.ValidationError{
+				{RuleID: "limits", Description: "spec must be set", Expression: "has(object.spec)", Resource: "Pod/debug"},
+			},
+		},
+		ChartPath:   dir,
+		ValuesFiles: []string{valuesFile},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, formatSARIF(&buf, report))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal(t, "helm-cel", driver.Name)
+	assert.Equal(
+		t, []sarifReportingDescriptor{
+			{
+				ID:                   "port",
+				ShortDescription:     sarifMessage{Text: "port must be valid"},
+				FullDescription:      sarifMessage{Text: "values.service.port <= 65535"},
+				DefaultConfiguration: sarifConfiguration{Level: "error"},
+			},
+			{
+				ID:                   "rule-2",
+				ShortDescription:     sarifMessage{Text: "debug should be off"},
+				FullDescription:      sarifMessage{Text: "values.debug == false"},
+				DefaultConfiguration: sarifConfiguration{Level: "warning"},
+			},
+			{
+				ID:                   "limits",
+				ShortDescription:     sarifMessage{Text: "spec must be set"},
+				FullDescription:      sarifMessage{Text: "has(object.spec)"},
+				DefaultConfiguration: sarifConfiguration{Level: "note"},
+			},
+		}, driver.Rules,
+	)
+
+	assert.Equal(
+		t, []sarifResult{
+			{
+				RuleID:    "port",
+				RuleIndex: 0,
+				Level:     "error",
+				Message:   sarifMessage{Text: "port must be valid (path: service.port, current value: 70000)"},
+				Locations: []sarifLocation{
+					{
+						PhysicalLocation: &sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "values.yaml"},
+							Region:           &sarifRegion{StartLine: 2, StartColumn: 3},
+						},
+					},
+				},
+			},
+			{
+				RuleID:    "rule-2",
+				RuleIndex: 1,
+				Level:     "warning",
+				Message:   sarifMessage{Text: "debug should be off (path: debug, current value: <nil>)"},
+				Locations: []sarifLocation{
+					{PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "values.cel.yaml"}}},
+				},
+			},
+			{
+				RuleID:    "limits",
+				RuleIndex: 2,
+				Level:     "note",
+				Message:   sarifMessage{Text: "spec must be set (resource: Pod/debug)"},
+				Locations: []sarifLocation{
+					{
+						PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "values.cel.yaml"}},
+						LogicalLocations: []sarifLogicalLocation{{Name: "Pod/debug", Kind: "resource"}},
+					},
+				},
+			},
+		}, log.Runs[0].Results,
+	)
+}
+
+func TestFormatSARIF_SharedExpression(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, formatSARIF(&buf, newSharedExpressionReport()))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Tool.Driver.Rules, 3)
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 2)
+	assert.Equal(t, "rule-1", results[0].RuleID)
+	assert.Equal(t, 0, results[0].RuleIndex)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Equal(t, "rule-2", results[1].RuleID)
+	assert.Equal(t, 1, results[1].RuleIndex)
+	assert.Equal(t, "warning", results[1].Level)
+}
+
+func TestFormatCharts(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	api := &Report{
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{RuleID: "port", Description: "port must be valid", Expression: "values.service.port <= 65535", Path: "service.port"},
+			},
+		},
+		ChartPath: filepath.Join(dir, "charts", "api"),
+	}
+	web := &Report{
+		Result: &models.ValidationResult{
+			Passed: []*models.PassedRule{
+				{RuleID: "replicas", Description: "replicas must be positive", Expression: "values.replicas > 0"},
+			},
+		},
+		ChartPath: filepath.Join(dir, "charts", "web"),
+	}
+
+	t.Run(
+		"json", func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, formatJSONCharts(&buf, []*Report{api, web}))
+
+			var output models.ChartsOutput
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+			assert.True(t, output.HasErrors)
+			assert.False(t, output.HasWarnings)
+			assert.Equal(t, models.Summary{Rules: 2, Passed: 1, Failed: 1, Errors: 1}, output.Summary)
+			require.Len(t, output.Charts, 2)
+			assert.True(t, output.Charts["charts/api"].HasErrors)
+			assert.False(t, output.Charts["charts/web"].HasErrors)
+		},
+	)
+
+	t.Run(
+		"sarif", func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, formatSARIFCharts(&buf, []*Report{api, web}))
+
+			var log sarifLog
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+			require.Len(t, log.Runs, 2)
+			assert.Equal(t, &sarifAutomationDetails{ID: "helm-cel/charts/api/"}, log.Runs[0].AutomationDetails)
+			assert.Len(t, log.Runs[0].Results, 1)
+			assert.Equal(t, &sarifAutomationDetails{ID: "helm-cel/charts/web/"}, log.Runs[1].AutomationDetails)
+			assert.Empty(t, log.Runs[1].Results)
+		},
+	)
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "helm-cel"
+	toolURI      = "https://github.com/idsulik/helm-cel"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool               `json:"tool"`
+	AutomationDetails *sarifAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []sarifResult           `json:"results"`
+}
+
+// sarifAutomationDetails tells the runs of several charts apart, code scanning requires a distinct category per run
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                     `json:"name"`
+	InformationURI string                     `json:"informationUri"`
+	Rules          []sarifReportingDescriptor `json:"rules"`
+}
+
+type sarifReportingDescriptor struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// sarifLevels maps rule severities to SARIF levels
+var sarifLevels = map[string]string{
+	"":        "error",
+	"error":   "error",
+	"warning": "warning",
+	"info":    "note",
+}
+
+// formatSARIF writes the report as a SARIF 2.1.0 log, with each enabled rule as a reporting descriptor
+// and each failure located in the values file setting the failing value
+func formatSARIF(w io.Writer, report *Report) error {
+	return writeSARIF(w, []sarifRun{sarifRunOf(report)})
+}
+
+// formatSARIFCharts writes a SARIF log with a run per chart, categorized by chart
+func formatSARIFCharts(w io.Writer, reports []*Report) error {
+	runs := make([]sarifRun, 0, len(reports))
+	for _, report := range reports {
+		run := sarifRunOf(report)
+		run.AutomationDetails = &sarifAutomationDetails{ID: toolName + "/" + chartName(report) + "/"}
+		runs = append(runs, run)
+	}
+	return writeSARIF(w, runs)
+}
+
+// sarifRunOf describes the validation of a chart as a SARIF run
+func sarifRunOf(report *Report) sarifRun {
+	rules := newRuleIndex(report.Rules)
+	locator := NewValuesLocator(report.ValuesFiles)
+
+	results := make([]sarifResult, 0)
+	for _, failures := range []struct {
+		level  string
+		errors []*models.ValidationError
+	}{
+		{"error", report.Result.Errors},
+		{"warning", report.Result.Warnings},
+		{"note", report.Result.Infos},
+	} {
+		for _, failure := range failures.errors {
+			index := rules.find(failure)
+			results = append(
+				results, sarifResult{
+					RuleID:    rules.ids[index],
+					RuleIndex: index,
+					Level:     failures.level,
+					Message:   sarifMessage{Text: failureMessage(failure)},
+					Locations: []sarifLocation{sarifFailureLocation(failure, rules.rules[index], locator)},
+				},
+			)
+		}
+	}
+
+	// Descriptors are listed after the results since failures without a known rule add one
+	driver := sarifDriver{Name: toolName, InformationURI: toolURI, Rules: make([]sarifReportingDescriptor, 0)}
+	for i, rule := range rules.rules {
+		driver.Rules = append(
+			driver.Rules, sarifReportingDescriptor{
+				ID:                   rules.ids[i],
+				ShortDescription:     sarifMessage{Text: rule.Desc},
+				FullDescription:      sarifMessage{Text: rule.Expr},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevels[rule.Severity]},
+			},
+		)
+	}
+
+	return sarifRun{Tool: sarifTool{Driver: driver}, Results: results}
+}
+
+func writeSARIF(w io.Writer, runs []sarifRun) error {
+	log := sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: runs}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to marshal output to SARIF: %v", err)
+	}
+	return nil
+}
+
+// sarifFailureLocation points to the values file setting the failing value, the rules file otherwise,
+// with the rendered resource as a logical location for failures on manifests
+func sarifFailureLocation(failure *models.ValidationError, rule models.Rule, locator *ValuesLocator) sarifLocation {
+	var location sarifLocation
+
+	if failure.Resource != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{Name: failure.Resource, Kind: "resource"}}
+	}
+	if found, ok := locateFailure(failure, rule, locator); ok {
+		location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: artifactURI(found.File)}}
+		if found.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: found.Line, StartColumn: found.Column}
+		}
+	}
+	return location
+}
+
+// artifactURI returns the path of a file relative to the working directory, usually the repository root in CI
+func artifactURI(file string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// failureMessage describes a failure with the path and value that made the rule fail
+func failureMessage(failure *models.ValidationError) string {
+	message := failure.Description
+	if failure.Resource != "" {
+		message += fmt.Sprintf(" (resource: %s)", failure.Resource)
+	}
+	if failure.Path != "" {
+		message += fmt.Sprintf(" (path: %s, current value: %v)", failure.Path, failure.Value)
+	}
+	return message
+}
+
+// ruleIndex identifies the enabled rules of a report, rules without an ID are named after their position
+type ruleIndex struct {
+	rules     []models.Rule
+	ids       []string
+	positions []int // of the rules in the report rules
+}
+
+func newRuleIndex(rules []models.Rule) *ruleIndex {
+	index := &ruleIndex{}
+	for i, rule := range rules {
+		if rule.IsDisabled() {
+			continue
+		}
+		id := rule.ID
+		if id == "" {
+			id = fmt.Sprintf("rule-%d", i+1)
+		}
+		index.rules = append(index.rules, rule)
+		index.ids = append(index.ids, id)
+		index.positions = append(index.positions, i)
+	}
+	return index
+}
+
+// find returns the index of the rule of a failure, adding a rule for failures that match none
+func (r *ruleIndex) find(failure *models.ValidationError) int {
+	for i, rule := range r.rules {
+		if matchesRule(rule, r.positions[i], failure.RuleID, failure.RuleIndex) {
+			return i
+		}
+	}
+
+	id := failure.RuleID
+	if id == "" {
+		id = fmt.Sprintf("rule-%d", failure.RuleIndex+1)
+	}
+	r.rules = append(r.rules, models.Rule{ID: failure.RuleID, Expr: failure.Expression, Desc: failure.Description})
+	r.ids = append(r.ids, id)
+	r.positions = append(r.positions, failure.RuleIndex)
+	return len(r.rules) - 1
+}
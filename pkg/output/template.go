@@ -0,0 +1,164 @@
+package output
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateFailure is a failure with its severity, as listed by the failures template function
+type TemplateFailure struct {
+	Severity string
+	*models.ValidationError
+}
+
+// TemplateFormatter renders the validation output with a Go text/template
+type TemplateFormatter struct {
+	name string
+	text string
+}
+
+// NewTemplateFormatter creates a formatter from the name of a built-in template or the path of a template file
+func NewTemplateFormatter(template string) (*TemplateFormatter, error) {
+	if template == "" {
+		return nil, fmt.Errorf("a template is required for the template output format (built-in templates: %s)",
+			strings.Join(TemplateNames(), ", "))
+	}
+
+	if content, err := builtinTemplates.ReadFile("templates/" + template + ".tmpl"); err == nil {
+		return &TemplateFormatter{name: template, text: string(content)}, nil
+	}
+
+	content, err := os.ReadFile(template)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to read template (built-in templates: %s): %v", strings.Join(TemplateNames(), ", "), err,
+		)
+	}
+	return &TemplateFormatter{name: template, text: string(content)}, nil
+}
+
+// TemplateNames returns the sorted names of the built-in templates
+func TemplateNames() []string {
+	entries, _ := builtinTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format renders the models.ValidationOutput of the report with the template
+func (f *TemplateFormatter) Format(w io.Writer, report *Report) error {
+	tmpl, err := template.New(f.name).Funcs(templateFuncs(report)).Parse(f.text)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, validationOutput(report)); err != nil {
+		return fmt.Errorf("failed to render template: %v", err)
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// templateFuncs returns the helper functions available to templates, bound to the report for locating failures
+func templateFuncs(report *Report) template.FuncMap {
+	rules := newRuleIndex(report.Rules)
+	locator := NewValuesLocator(report.ValuesFiles)
+
+	return template.FuncMap{
+		// failures lists the errors, warnings and infos of the output with their severity
+		"failures": func(output models.ValidationOutput) []TemplateFailure {
+			failures := make([]TemplateFailure, 0)
+			for _, severity := range []struct {
+				name   string
+				errors []*models.ValidationError
+			}{
+				{"error", output.Result.Errors},
+				{"warning", output.Result.Warnings},
+				{"info", output.Result.Infos},
+			} {
+				for _, failure := range severity.errors {
+					failures = append(failures, TemplateFailure{Severity: severity.name, ValidationError: failure})
+				}
+			}
+			return failures
+		},
+		// locate returns the values file position setting the failing value, relative to the working directory
+		"locate": func(failure TemplateFailure) Location {
+			index := rules.find(failure.ValidationError)
+			found, ok := locateFailure(failure.ValidationError, rules.rules[index], locator)
+			if !ok {
+				return Location{}
+			}
+			found.File = artifactURI(found.File)
+			return found
+		},
+		// message describes a failure with its resource, path and current value
+		"message": func(failure TemplateFailure) string {
+			return failureMessage(failure.ValidationError)
+		},
+		"json": func(v any) (string, error) {
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(v); err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(buf.String(), "\n"), nil
+		},
+		"csv": func(fields ...any) (string, error) {
+			record := make([]string, 0, len(fields))
+			for _, field := range fields {
+				record = append(record, templateString(field))
+			}
+			var buf bytes.Buffer
+			writer := csv.NewWriter(&buf)
+			if err := writer.Write(record); err != nil {
+				return "", err
+			}
+			writer.Flush()
+			return strings.TrimSuffix(buf.String(), "\n"), writer.Error()
+		},
+		"xml": func(v any) (string, error) {
+			var buf bytes.Buffer
+			if err := xml.EscapeText(&buf, []byte(templateString(v))); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		},
+		"markdown": func(v any) string {
+			return strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>").Replace(templateString(v))
+		},
+		"str":   templateString,
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"trim":  strings.TrimSpace,
+	}
+}
+
+// templateString formats a value for templates, with nil as an empty string
+func templateString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
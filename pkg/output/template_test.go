@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateNames(t *testing.T) {
+	assert.Equal(t, []string{"checkstyle", "csv", "markdown"}, TemplateNames())
+}
+
+func TestTemplateFormatter_Builtin(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "markdown",
+			template: "markdown",
+			expected: `### ❌ helm-cel validation failed
+
+0 of 3 rule(s) passed, 3 failed, 0 skipped.
+
+| Severity | Rule | Description | Path | Value |
+| --- | --- | --- | --- | --- |
+| error | port | port must be valid | service.port | 70000 |
+| warning |  | debug should be off | debug |  |
+| info | limits | spec must be set |  |  |
+`,
+		},
+		{
+			name:     "csv",
+			template: "csv",
+			expected: `severity,rule_id,description,expression,resource,path,value
+error,port,port must be valid,values.service.port <= 65535,,service.port,70000
+warning,,debug should be off,values.debug == false,,debug,
+info,limits,spec must be set,has(object.spec),Pod/debug,,
+`,
+		},
+		{
+			name:     "checkstyle",
+			template: "checkstyle",
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="values.yaml">
+    <error line="2" column="3" severity="error" message="port must be valid (path: service.port, current value: 70000)" source="helm-cel.port"/>
+  </file>
+  <file name="values.cel.yaml">
+    <error severity="warning" message="debug should be off (path: debug, current value: &lt;nil&gt;)" source="helm-cel"/>
+  </file>
+  <file name="values.cel.yaml">
+    <error severity="info" message="spec must be set (resource: Pod/debug)" source="helm-cel.limits"/>
+  </file>
+</checkstyle>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				formatter, err := NewTemplateFormatter(tt.template)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				require.NoError(t, formatter.Format(&buf, newAnnotationsReport(t)))
+				assert.Equal(t, tt.expected, buf.String())
+			},
+		)
+	}
+}
+
+func TestTemplateFormatter_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(
+		t, os.WriteFile(
+			file,
+			[]byte(`{{ .Summary.Failed }} failed{{ range failures . }}
+{{ upper .Severity }} {{ json .Value }} {{ with locate . }}{{ .File }}:{{ .Line }}{{ end }}{{ end }}
+`),
+			0644,
+		),
+	)
+
+	formatter, err := NewTemplateFormatter(file)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, formatter.Format(&buf, newAnnotationsReport(t)))
+	assert.Equal(t, "3 failed\nERROR 70000 values.yaml:2\nWARNING null values.cel.yaml:0\nINFO null values.cel.yaml:0\n", buf.String())
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	_, err := NewTemplateFormatter("")
+	assert.ErrorContains(t, err, "a template is required")
+
+	_, err = NewTemplateFormatter(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to read template (built-in templates: checkstyle, csv, markdown)")
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.tmpl")
+	require.NoError(t, os.WriteFile(invalid, []byte("{{ .Summary"), 0644))
+	formatter, err := NewTemplateFormatter(invalid)
+	require.NoError(t, err)
+	assert.ErrorContains(t, formatter.Format(&bytes.Buffer{}, newAnnotationsReport(t)), "failed to parse template")
+
+	unknown := filepath.Join(dir, "unknown.tmpl")
+	require.NoError(t, os.WriteFile(unknown, []byte("{{ .Unknown }}"), 0644))
+	formatter, err = NewTemplateFormatter(unknown)
+	require.NoError(t, err)
+	assert.ErrorContains(t, formatter.Format(&bytes.Buffer{}, newAnnotationsReport(t)), "failed to render template")
+}
@@ -0,0 +1,478 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiRed       = "\x1b[1;31m"
+	ansiGreen     = "\x1b[1;32m"
+	ansiYellow    = "\x1b[1;33m"
+	ansiBlue      = "\x1b[1;34m"
+	ansiGray      = "\x1b[90m"
+	ansiHighlight = "\x1b[1;4m"
+
+	textSeparator = "-------------------------------------------------"
+	// textIndent is the indentation of the details of a rule
+	textIndent = "   "
+)
+
+// textSymbols are the markers of each kind of line, with emoji and as plain text for logs that mangle Unicode
+var textSymbols = map[string][2]string{
+	"error":    {"❌", "[ERROR]"},
+	"warning":  {"⚠️", "[WARNING]"},
+	"info":     {"ℹ️", "[INFO]"},
+	"passed":   {"✅", "[PASSED]"},
+	"skipped":  {"⏭️", "[SKIPPED]"},
+	"failed":   {"❌", "[FAILED]"},
+	"warnings": {"⚠️✅", "[OK]"},
+	"success":  {"✅", "[OK]"},
+}
+
+// TextOptions configures the text output
+type TextOptions struct {
+	Color   bool // use ANSI colors and highlight the failing path in expressions
+	Emoji   bool // mark lines with emoji rather than plain text labels
+	Quiet   bool // only print errors
+	Verbose bool // also list the passed and skipped rules
+	Width   int  // wrap expressions to this width, 0 disables wrapping
+}
+
+// TextFormatter writes a validation report for terminals and CI logs
+type TextFormatter struct {
+	options TextOptions
+}
+
+// NewTextFormatter creates a text formatter with the given options
+func NewTextFormatter(options TextOptions) *TextFormatter {
+	return &TextFormatter{options: options}
+}
+
+// Format writes the failures grouped by severity, followed by the validation status and a summary line
+func (f *TextFormatter) Format(w io.Writer, report *Report) error {
+	var out strings.Builder
+	f.writeReport(&out, report)
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// FormatCharts writes the output of each chart under a header, followed by a line counting the charts by status
+func (f *TextFormatter) FormatCharts(w io.Writer, reports []*Report) error {
+	var out strings.Builder
+	f.writeReports(&out, reports, chartName)
+	if !f.options.Quiet {
+		f.writeCount(&out, reports, "chart(s)")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// FormatMatrix writes the output of each environment under a header, followed by a table of the outcome of each rule
+// in each environment and a line counting the environments by status
+func (f *TextFormatter) FormatMatrix(w io.Writer, reports []*Report) error {
+	var out strings.Builder
+	f.writeReports(
+		&out, reports, func(report *Report) string {
+			files := make([]string, 0, len(report.ValuesFiles))
+			for _, file := range report.ValuesFiles {
+				files = append(files, relativePath(report.ChartPath, file))
+			}
+			return fmt.Sprintf("%s (%s)", report.Environment, strings.Join(files, ", "))
+		},
+	)
+	if !f.options.Quiet {
+		f.writeMatrix(&out, reports)
+		f.writeCount(&out, reports, "environment(s)")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// writeReports writes each report under a header, quiet mode skips the reports without errors
+func (f *TextFormatter) writeReports(out *strings.Builder, reports []*Report, header func(*Report) string) {
+	for _, report := range reports {
+		if f.options.Quiet && !report.Result.HasErrors() {
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(f.paint(ansiBold, "==> "+header(report)) + "\n")
+		f.writeReport(out, report)
+	}
+}
+
+// writeCount writes a line counting the reports by status
+func (f *TextFormatter) writeCount(out *strings.Builder, reports []*Report, noun string) {
+	failed, warnings := 0, 0
+	for _, report := range reports {
+		switch {
+		case report.Failed:
+			failed++
+		case len(report.Result.Warnings) > 0:
+			warnings++
+		}
+	}
+
+	color := ansiGreen
+	switch {
+	case failed > 0:
+		color = ansiRed
+	case warnings > 0:
+		color = ansiYellow
+	}
+	out.WriteString(
+		"\n" + f.paint(
+			color, fmt.Sprintf(
+				"Validated %d %s: %d failed, %d with warnings, %d successful",
+				len(reports), noun, failed, warnings, len(reports)-failed-warnings,
+			),
+		) + "\n",
+	)
+}
+
+// writeMatrix writes a table with a row per rule and a column per environment
+func (f *TextFormatter) writeMatrix(out *strings.Builder, reports []*Report) {
+	if len(reports) == 0 || len(reports[0].Rules) == 0 {
+		return
+	}
+
+	header := []string{"Rule"}
+	for _, report := range reports {
+		header = append(header, report.Environment)
+	}
+	rows := [][]string{header}
+	for position, rule := range reports[0].Rules {
+		row := []string{rule.Desc}
+		for _, report := range reports {
+			row = append(row, ruleStatus(rule, position, report.Result))
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	out.WriteString("\n" + f.paint(ansiBold, "Results by environment:") + "\n\n")
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			padding := ""
+			if i < len(row)-1 {
+				padding = strings.Repeat(" ", widths[i]-len(cell)+2)
+			}
+			switch {
+			case r == 0:
+				cell = f.paint(ansiBold, cell)
+			case i > 0:
+				cell = f.paint(statusColor(cell), cell)
+			}
+			line.WriteString(cell + padding)
+		}
+		out.WriteString(line.String() + "\n")
+	}
+}
+
+func (f *TextFormatter) writeReport(out *strings.Builder, report *Report) {
+	result := report.Result
+	if f.options.Quiet {
+		if len(result.Errors) > 0 {
+			f.writeFailures(out, "error", "Found %d error(s):", result.Errors)
+			out.WriteString("\n")
+		}
+		return
+	}
+
+	if f.options.Verbose && len(result.Passed)+len(result.Skipped) > 0 {
+		f.writeSections(
+			out,
+			func(out *strings.Builder) bool { return f.writePassed(out, result.Passed) },
+			func(out *strings.Builder) bool { return f.writeSkipped(out, result.Skipped) },
+		)
+		out.WriteString("\n" + f.paint(ansiGray, textSeparator) + "\n")
+	}
+
+	if result.HasErrors() || len(result.Warnings) > 0 || len(result.Infos) > 0 {
+		f.writeSections(
+			out,
+			func(out *strings.Builder) bool {
+				return f.writeFailures(out, "error", "Found %d error(s):", result.Errors)
+			},
+			func(out *strings.Builder) bool {
+				return f.writeFailures(out, "warning", "Found %d warning(s):", result.Warnings)
+			},
+			func(out *strings.Builder) bool {
+				return f.writeFailures(out, "info", "Found %d info(s):", result.Infos)
+			},
+		)
+		out.WriteString("\n" + f.paint(ansiGray, textSeparator) + "\n")
+	}
+
+	switch {
+	case report.Failed:
+		out.WriteString(f.paint(ansiRed, f.symbol("failed")+" Values validation failed") + "\n")
+	case result.HasErrors():
+		out.WriteString(f.paint(ansiRed, f.symbol("failed")+" Values validation failed (ignored due to --fail-on)") + "\n")
+	case len(result.Warnings) > 0:
+		out.WriteString(f.paint(ansiYellow, f.symbol("warnings")+" Values validation successful with warnings!") + "\n")
+	default:
+		out.WriteString(f.paint(ansiGreen, f.symbol("success")+" Values validation successful!") + "\n")
+	}
+	out.WriteString(f.summary(result) + "\n")
+}
+
+// writeSections writes the non-empty sections separated by a blank line
+func (f *TextFormatter) writeSections(out *strings.Builder, sections ...func(out *strings.Builder) bool) {
+	written := false
+	for _, section := range sections {
+		var content strings.Builder
+		if !section(&content) {
+			continue
+		}
+		if written {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(content.String())
+		written = true
+	}
+}
+
+func (f *TextFormatter) writeFailures(
+	out *strings.Builder,
+	severity, title string,
+	failures []*models.ValidationError,
+) bool {
+	if len(failures) == 0 {
+		return false
+	}
+
+	color := severityColor(severity)
+	out.WriteString(f.paint(color, fmt.Sprintf(title, len(failures))) + "\n\n")
+	for i, failure := range failures {
+		out.WriteString(f.paint(color, f.symbol(severity)+" "+failure.Description) + "\n")
+		if failure.RuleID != "" {
+			out.WriteString(f.detail("ID", failure.RuleID))
+		}
+		if failure.Resource != "" {
+			out.WriteString(f.detail("Resource", failure.Resource))
+		}
+		out.WriteString(f.expression(failure))
+		if failure.Path != "" {
+			out.WriteString(f.detail("Path", f.paint(ansiHighlight, failure.Path)))
+		}
+		if failure.Value != nil {
+			out.WriteString(textIndent + "Current value: " + fmt.Sprintf("%v", failure.Value))
+		} else {
+			out.WriteString(textIndent + "Current value: <nil>")
+		}
+		if i < len(failures)-1 {
+			out.WriteString("\n\n")
+		}
+	}
+	return true
+}
+
+func (f *TextFormatter) writePassed(out *strings.Builder, passed []*models.PassedRule) bool {
+	if len(passed) == 0 {
+		return false
+	}
+
+	out.WriteString(f.paint(ansiGreen, fmt.Sprintf("Passed %d rule(s):", len(passed))) + "\n\n")
+	for i, pass := range passed {
+		out.WriteString(f.paint(ansiGreen, f.symbol("passed")+" "+pass.Description) + "\n")
+		if pass.RuleID != "" {
+			out.WriteString(f.detail("ID", pass.RuleID))
+		}
+		if pass.Resource != "" {
+			out.WriteString(f.detail("Resource", pass.Resource))
+		}
+		out.WriteString(strings.TrimSuffix(f.wrap("Rule: ", pass.Expression, ""), "\n"))
+		if i < len(passed)-1 {
+			out.WriteString("\n\n")
+		}
+	}
+	return true
+}
+
+func (f *TextFormatter) writeSkipped(out *strings.Builder, skipped []*models.SkippedRule) bool {
+	if len(skipped) == 0 {
+		return false
+	}
+
+	out.WriteString(f.paint(ansiGray, fmt.Sprintf("Skipped %d rule(s):", len(skipped))) + "\n\n")
+	for i, skip := range skipped {
+		out.WriteString(f.paint(ansiGray, f.symbol("skipped")+" "+skip.Description) + "\n")
+		if skip.RuleID != "" {
+			out.WriteString(f.detail("ID", skip.RuleID))
+		}
+		out.WriteString(f.wrap("Rule: ", skip.Expression, ""))
+		out.WriteString(textIndent + "Reason: " + skip.Reason)
+		if i < len(skipped)-1 {
+			out.WriteString("\n\n")
+		}
+	}
+	return true
+}
+
+// summary counts the rules by outcome and the failures by severity, with the evaluation time in verbose mode
+func (f *TextFormatter) summary(result *models.ValidationResult) string {
+	summary := result.Summary()
+	line := fmt.Sprintf(
+		"Summary: %d of %d rule(s) passed, %d failed, %d skipped (%d error(s), %d warning(s), %d info(s))",
+		summary.Passed, summary.Rules, summary.Failed, summary.Skipped, summary.Errors, summary.Warnings, summary.Infos,
+	)
+	if f.options.Verbose {
+		line += fmt.Sprintf(" in %.1fms", summary.DurationMs)
+	}
+	return f.paint(ansiBold, line)
+}
+
+func (f *TextFormatter) detail(label, value string) string {
+	return fmt.Sprintf("%s%s: %s\n", textIndent, label, value)
+}
+
+// expression writes the expression of a failure, wrapped to the width, with the failing path highlighted
+func (f *TextFormatter) expression(failure *models.ValidationError) string {
+	token := ""
+	if failure.Path != "" {
+		variable := "values"
+		if failure.Resource != "" {
+			variable = "object"
+		}
+		token = variable + "." + strings.TrimPrefix(failure.Path, variable+".")
+	}
+	return f.wrap("Rule: ", failure.Expression, token)
+}
+
+// wrap writes a labelled detail, breaking its value on spaces so lines fit the width, with the token highlighted
+func (f *TextFormatter) wrap(label, value, token string) string {
+	prefix := textIndent + label
+	lines := []string{value}
+	if f.options.Width > 0 {
+		lines = wrapWords(value, f.options.Width-len(prefix))
+	}
+
+	var out strings.Builder
+	for i, line := range lines {
+		if i == 0 {
+			out.WriteString(prefix)
+		} else {
+			out.WriteString(strings.Repeat(" ", len(prefix)))
+		}
+		if token != "" && f.options.Color {
+			line = highlight(line, token)
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+func (f *TextFormatter) symbol(kind string) string {
+	if f.options.Emoji {
+		return textSymbols[kind][0]
+	}
+	return textSymbols[kind][1]
+}
+
+func (f *TextFormatter) paint(color, text string) string {
+	if !f.options.Color {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+func statusColor(status string) string {
+	switch status {
+	case "passed":
+		return ansiGreen
+	case "skipped":
+		return ansiGray
+	default:
+		return severityColor(status)
+	}
+}
+
+func severityColor(severity string) string {
+	switch severity {
+	case "warning":
+		return ansiYellow
+	case "info":
+		return ansiBlue
+	default:
+		return ansiRed
+	}
+}
+
+// wrapWords breaks text on spaces into lines of at most width characters, words longer than the width get their own line
+func wrapWords(text string, width int) []string {
+	words := strings.Split(text, " ")
+	if width <= 0 || len(text) <= width {
+		return []string{text}
+	}
+
+	lines := make([]string, 0)
+	line := words[0]
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
+
+// highlight marks the occurrences of a path in a line of an expression, ignoring longer paths it is a prefix of
+func highlight(line, token string) string {
+	var out strings.Builder
+	for {
+		i := strings.Index(line, token)
+		if i < 0 {
+			out.WriteString(line)
+			return out.String()
+		}
+		end := i + len(token)
+		if (i > 0 && isIdentifierChar(line[i-1])) || !endsPath(line[end:]) {
+			out.WriteString(line[:end])
+			line = line[end:]
+			continue
+		}
+		out.WriteString(line[:i] + ansiHighlight + token + ansiReset)
+		line = line[end:]
+	}
+}
+
+// endsPath reports whether the rest of a line after a path doesn't select a field of it, method calls are allowed
+func endsPath(rest string) bool {
+	if rest == "" {
+		return true
+	}
+	if isIdentifierChar(rest[0]) {
+		return false
+	}
+	if rest[0] != '.' {
+		return true
+	}
+	i := 1
+	for i < len(rest) && isIdentifierChar(rest[i]) {
+		i++
+	}
+	return i < len(rest) && rest[i] == '('
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,318 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTextReport() *Report {
+	return &Report{
+		Result: &models.ValidationResult{
+			Errors: []*models.ValidationError{
+				{RuleID: "port", Description: "port must be valid", Expression: "values.service.port <= 65535", Path: "service.port", Value: 70000},
+			},
+			Warnings: []*models.ValidationError{
+				{Description: "debug should be off", Expression: "values.debug == false", Path: "debug", RuleIndex: 1},
+			},
+			Passed: []*models.PassedRule{
+				{RuleID: "replicas", Description: "replicas must be positive", Expression: "values.replicas > 0"},
+			},
+			Skipped: []*models.SkippedRule{
+				{RuleID: "limits", Description: "limits must be set", Expression: "has(object.spec)", Reason: "no rendered manifests"},
+			},
+		},
+	}
+}
+
+func failedReport(report *Report) *Report {
+	report.Failed = true
+	return report
+}
+
+func TestTextFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  TextOptions
+		report   *Report
+		expected string
+	}{
+		{
+			name:    "failed",
+			options: TextOptions{Emoji: true},
+			report:  failedReport(newTextReport()),
+			expected: `Found 1 error(s):
+
+❌ port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+
+Found 1 warning(s):
+
+⚠️ debug should be off
+   Rule: values.debug == false
+   Path: debug
+   Current value: <nil>
+-------------------------------------------------
+❌ Values validation failed
+Summary: 1 of 4 rule(s) passed, 2 failed, 1 skipped (1 error(s), 1 warning(s), 0 info(s))
+`,
+		},
+		{
+			name:    "errors ignored without emoji",
+			options: TextOptions{},
+			report:  newTextReport(),
+			expected: `Found 1 error(s):
+
+[ERROR] port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+
+Found 1 warning(s):
+
+[WARNING] debug should be off
+   Rule: values.debug == false
+   Path: debug
+   Current value: <nil>
+-------------------------------------------------
+[FAILED] Values validation failed (ignored due to --fail-on)
+Summary: 1 of 4 rule(s) passed, 2 failed, 1 skipped (1 error(s), 1 warning(s), 0 info(s))
+`,
+		},
+		{
+			name:    "verbose",
+			options: TextOptions{Emoji: true, Verbose: true},
+			report: &Report{
+				Result: &models.ValidationResult{
+					Passed:  newTextReport().Result.Passed,
+					Skipped: newTextReport().Result.Skipped,
+				},
+			},
+			expected: `Passed 1 rule(s):
+
+✅ replicas must be positive
+   ID: replicas
+   Rule: values.replicas > 0
+
+Skipped 1 rule(s):
+
+⏭️ limits must be set
+   ID: limits
+   Rule: has(object.spec)
+   Reason: no rendered manifests
+-------------------------------------------------
+✅ Values validation successful!
+Summary: 1 of 2 rule(s) passed, 0 failed, 1 skipped (0 error(s), 0 warning(s), 0 info(s)) in 0.0ms
+`,
+		},
+		{
+			name:    "quiet",
+			options: TextOptions{Quiet: true, Verbose: true},
+			report:  newTextReport(),
+			expected: `Found 1 error(s):
+
+[ERROR] port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+`,
+		},
+		{
+			name:     "quiet without errors",
+			options:  TextOptions{Quiet: true},
+			report:   &Report{Result: &models.ValidationResult{Warnings: newTextReport().Result.Warnings}},
+			expected: "",
+		},
+		{
+			name:    "color and width",
+			options: TextOptions{Color: true, Width: 30},
+			report: &Report{
+				Failed: true,
+				Result: &models.ValidationResult{
+					Errors: []*models.ValidationError{
+						{
+							Description: "tag must be a version",
+							Expression:  "has(values.image.tag) && values.image.tag.matches('^v')",
+							Path:        "image.tag",
+							Value:       "latest",
+						},
+					},
+				},
+			},
+			expected: "\x1b[1;31mFound 1 error(s):\x1b[0m\n\n" +
+				"\x1b[1;31m[ERROR] tag must be a version\x1b[0m\n" +
+				"   Rule: has(\x1b[1;4mvalues.image.tag\x1b[0m)\n" +
+				"         &&\n" +
+				"         \x1b[1;4mvalues.image.tag\x1b[0m.matches('^v')\n" +
+				"   Path: \x1b[1;4mimage.tag\x1b[0m\n" +
+				"   Current value: latest\n" +
+				"\x1b[90m-------------------------------------------------\x1b[0m\n" +
+				"\x1b[1;31m[FAILED] Values validation failed\x1b[0m\n" +
+				"\x1b[1mSummary: 0 of 1 rule(s) passed, 1 failed, 0 skipped (1 error(s), 0 warning(s), 0 info(s))\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				require.NoError(t, NewTextFormatter(tt.options).Format(&buf, tt.report))
+				assert.Equal(t, tt.expected, buf.String())
+			},
+		)
+	}
+}
+
+func TestTextFormatter_FormatCharts(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	api := &Report{
+		Failed:    true,
+		Result:    &models.ValidationResult{Errors: newTextReport().Result.Errors},
+		ChartPath: filepath.Join(dir, "charts", "api"),
+	}
+	web := &Report{
+		Result:    &models.ValidationResult{Warnings: newTextReport().Result.Warnings},
+		ChartPath: filepath.Join(dir, "charts", "web"),
+	}
+
+	tests := []struct {
+		name     string
+		options  TextOptions
+		expected string
+	}{
+		{
+			name: "all charts",
+			expected: `==> charts/api
+Found 1 error(s):
+
+[ERROR] port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+-------------------------------------------------
+[FAILED] Values validation failed
+Summary: 0 of 1 rule(s) passed, 1 failed, 0 skipped (1 error(s), 0 warning(s), 0 info(s))
+
+==> charts/web
+Found 1 warning(s):
+
+[WARNING] debug should be off
+   Rule: values.debug == false
+   Path: debug
+   Current value: <nil>
+-------------------------------------------------
+[OK] Values validation successful with warnings!
+Summary: 0 of 1 rule(s) passed, 1 failed, 0 skipped (0 error(s), 1 warning(s), 0 info(s))
+
+Validated 2 chart(s): 1 failed, 1 with warnings, 0 successful
+`,
+		},
+		{
+			name:    "quiet",
+			options: TextOptions{Quiet: true},
+			expected: `==> charts/api
+Found 1 error(s):
+
+[ERROR] port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				require.NoError(t, NewTextFormatter(tt.options).FormatCharts(&buf, []*Report{api, web}))
+				assert.Equal(t, tt.expected, buf.String())
+			},
+		)
+	}
+}
+
+func TestTextFormatter_FormatMatrix(t *testing.T) {
+	dir := t.TempDir()
+	reports := newMatrixReports()
+	reports[0].ChartPath, reports[0].ValuesFiles = dir, []string{filepath.Join(dir, "values.yaml"), filepath.Join(dir, "dev.yaml")}
+	reports[1].ChartPath, reports[1].ValuesFiles = dir, []string{filepath.Join(dir, "values.yaml"), filepath.Join(dir, "prod.yaml")}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewTextFormatter(TextOptions{}).FormatMatrix(&buf, reports))
+	assert.Equal(
+		t, `==> dev (values.yaml, dev.yaml)
+Found 1 warning(s):
+
+[WARNING] debug should be off
+   Rule: values.debug == false
+   Path: debug
+   Current value: true
+-------------------------------------------------
+[OK] Values validation successful with warnings!
+Summary: 1 of 3 rule(s) passed, 1 failed, 1 skipped (0 error(s), 1 warning(s), 0 info(s))
+
+==> prod (values.yaml, prod.yaml)
+Found 1 error(s):
+
+[ERROR] port must be valid
+   ID: port
+   Rule: values.service.port <= 65535
+   Path: service.port
+   Current value: 70000
+-------------------------------------------------
+[FAILED] Values validation failed
+Summary: 1 of 3 rule(s) passed, 1 failed, 1 skipped (1 error(s), 0 warning(s), 0 info(s))
+
+Results by environment:
+
+Rule                 dev      prod
+port must be valid   passed   error
+debug should be off  warning  passed
+spec must be set     skipped  skipped
+
+Validated 2 environment(s): 1 failed, 1 with warnings, 0 successful
+`, buf.String(),
+	)
+}
+
+func TestWrapWords(t *testing.T) {
+	assert.Equal(t, []string{"a && b"}, wrapWords("a && b", 0))
+	assert.Equal(t, []string{"a && b"}, wrapWords("a && b", 10))
+	assert.Equal(t, []string{"values.a &&", "values.b"}, wrapWords("values.a && values.b", 12))
+	assert.Equal(t, []string{"values.image.tag", "&& b"}, wrapWords("values.image.tag && b", 5))
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"values.port > 0", "\x1b[1;4mvalues.port\x1b[0m > 0"},
+		{"size(values.port.name) > 0", "size(values.port.name) > 0"},
+		{"values.portName > 0", "values.portName > 0"},
+		{"myvalues.port > 0", "myvalues.port > 0"},
+		{"values.port.startsWith('x')", "\x1b[1;4mvalues.port\x1b[0m.startsWith('x')"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, highlight(tt.line, "values.port"), tt.line)
+	}
+}
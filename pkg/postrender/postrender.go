@@ -0,0 +1,143 @@
+package postrender
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+)
+
+const (
+	// Exit codes of a post-render run, any other exit code than 0 fails the helm command
+	ExitSuccess = 0
+	ExitFailure = 1
+)
+
+// Options configures a post-render run
+type Options struct {
+	ChartPath   string   // rules and values files are relative to the chart
+	RulesFiles  []string // rules files, only the rules with a match are validated
+	ValuesFiles []string // values files exposed to rules as values
+	Profile     string
+	FailOn      string // lowest severity that fails the run: error, warning, info, or none
+}
+
+// NewOptionsFromEnv creates Options from the HELM_CEL_* environment variables, since helm passes no flags to
+// post-renderers
+func NewOptionsFromEnv() Options {
+	return Options{
+		ChartPath:   EnvOrDefault("HELM_CEL_CHART", "."),
+		RulesFiles:  EnvList("HELM_CEL_RULES_FILES", []string{"values.cel.yaml"}),
+		ValuesFiles: EnvList("HELM_CEL_VALUES_FILES", nil),
+		Profile:     os.Getenv("HELM_CEL_PROFILE"),
+		FailOn:      EnvOrDefault("HELM_CEL_FAIL_ON", "error"),
+	}
+}
+
+// Run reads the rendered manifests from in, validates them against the rules with a match and writes them unchanged
+// to out. Warnings and infos below the fail-on threshold are written to errOut. It returns ExitFailure with the
+// validation result as error if the run fails the helm command.
+func Run(in io.Reader, out, errOut io.Writer, opts Options) (int, error) {
+	switch opts.FailOn {
+	case "error", "warning", "info", "none":
+	default:
+		return ExitFailure, fmt.Errorf("invalid --fail-on value '%s' (must be one of error, warning, info, none)", opts.FailOn)
+	}
+
+	absPath, err := filepath.Abs(opts.ChartPath)
+	if err != nil {
+		return ExitFailure, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	// Manifests are passed through unchanged, so they are kept as read
+	rendered, err := io.ReadAll(in)
+	if err != nil {
+		return ExitFailure, fmt.Errorf("failed to read rendered manifests: %v", err)
+	}
+	manifests, err := validator.NewManifestsLoader().LoadManifests(bytes.NewReader(rendered))
+	if err != nil {
+		return ExitFailure, fmt.Errorf("failed to load rendered manifests: %v", err)
+	}
+
+	v := validator.New(validator.WithProfile(opts.Profile))
+	values := make(map[string]any)
+	if len(opts.ValuesFiles) > 0 {
+		values, err = v.LoadChartValues(absPath, opts.ValuesFiles)
+		if err != nil {
+			return ExitFailure, err
+		}
+	}
+
+	rules, err := v.LoadChartRules(absPath, opts.RulesFiles)
+	if err != nil {
+		return ExitFailure, err
+	}
+
+	// Only manifest-level rules apply, the values given to helm are not available to post-renderers
+	manifestRules := make([]models.Rule, 0, len(rules.Rules))
+	for _, rule := range rules.Rules {
+		if rule.Match != nil {
+			manifestRules = append(manifestRules, rule)
+		}
+	}
+	rules.Rules = manifestRules
+
+	result, err := v.ValidateManifests(values, manifests, rules)
+	if err != nil {
+		return ExitFailure, err
+	}
+
+	if fails(result, opts.FailOn) {
+		return ExitFailure, result
+	}
+	if len(result.Warnings) > 0 || len(result.Infos) > 0 {
+		_, _ = fmt.Fprintln(errOut, result.Error())
+	}
+
+	if _, err := out.Write(rendered); err != nil {
+		return ExitFailure, fmt.Errorf("failed to write manifests: %v", err)
+	}
+	return ExitSuccess, nil
+}
+
+// fails reports whether the result has failures at or above the fail-on severity
+func fails(result *models.ValidationResult, failOn string) bool {
+	switch failOn {
+	case "none":
+		return false
+	case "info":
+		return result.HasErrors() || len(result.Warnings) > 0 || len(result.Infos) > 0
+	case "warning":
+		return result.HasErrors() || len(result.Warnings) > 0
+	default:
+		return result.HasErrors()
+	}
+}
+
+// EnvOrDefault returns the value of the environment variable, or the default if it is unset or empty
+func EnvOrDefault(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+// EnvList returns the comma-separated values of the environment variable, or the default if it is unset or empty
+func EnvList(name string, def []string) []string {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
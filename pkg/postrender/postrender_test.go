@@ -0,0 +1,172 @@
+package postrender
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  - id: replicas
+    match:
+      kinds: ["Deployment"]
+    expr: "object.spec.replicas >= 2"
+    desc: "deployments must be highly available"
+  - id: labels
+    match: {}
+    expr: "has(object.metadata.labels)"
+    desc: "manifests should be labeled"
+    severity: warning
+  - id: debug
+    expr: "values.debug == true"
+    desc: "values rules are not validated"
+`
+
+// rendered keeps comments and formatting that a re-encoding of the manifests would lose
+const rendered = `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: {app: app}
+spec:
+  replicas: %d
+---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`
+
+func TestNewOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Options
+	}{
+		{
+			name: "defaults",
+			want: Options{ChartPath: ".", RulesFiles: []string{"values.cel.yaml"}, FailOn: "error"},
+		},
+		{
+			name: "environment variables",
+			env: map[string]string{
+				"HELM_CEL_CHART":        "./mychart",
+				"HELM_CEL_RULES_FILES":  " base.cel.yaml, ,prod.cel.yaml ",
+				"HELM_CEL_VALUES_FILES": "values.yaml,prod.yaml",
+				"HELM_CEL_PROFILE":      "strict",
+				"HELM_CEL_FAIL_ON":      "warning",
+			},
+			want: Options{
+				ChartPath:   "./mychart",
+				RulesFiles:  []string{"base.cel.yaml", "prod.cel.yaml"},
+				ValuesFiles: []string{"values.yaml", "prod.yaml"},
+				Profile:     "strict",
+				FailOn:      "warning",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				for _, name := range []string{
+					"HELM_CEL_CHART", "HELM_CEL_RULES_FILES", "HELM_CEL_VALUES_FILES", "HELM_CEL_PROFILE", "HELM_CEL_FAIL_ON",
+				} {
+					t.Setenv(name, tt.env[name])
+				}
+
+				assert.Equal(t, tt.want, NewOptionsFromEnv())
+			},
+		)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		replicas   int
+		failOn     string
+		wantCode   int
+		wantErr    string
+		wantErrOut string
+	}{
+		{
+			name:     "valid manifests are passed through and values rules are ignored",
+			replicas: 3,
+			failOn:   "error",
+			wantCode: ExitSuccess,
+		},
+		{
+			name:       "warnings are reported without failing",
+			replicas:   3,
+			failOn:     "error",
+			wantCode:   ExitSuccess,
+			wantErrOut: "manifests should be labeled",
+		},
+		{
+			name:     "errors fail the run",
+			replicas: 1,
+			failOn:   "error",
+			wantCode: ExitFailure,
+			wantErr:  "deployments must be highly available",
+		},
+		{
+			name:     "warnings fail the run with fail-on warning",
+			replicas: 3,
+			failOn:   "warning",
+			wantCode: ExitFailure,
+			wantErr:  "manifests should be labeled",
+		},
+		{
+			name:     "nothing fails the run with fail-on none",
+			replicas: 1,
+			failOn:   "none",
+			wantCode: ExitSuccess,
+		},
+		{
+			name:     "invalid fail-on",
+			replicas: 3,
+			failOn:   "fatal",
+			wantCode: ExitFailure,
+			wantErr:  "invalid --fail-on value 'fatal'",
+		},
+	}
+
+	chartPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(chartPath, "values.cel.yaml"), []byte(testRules), 0644))
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				in := fmt.Sprintf(rendered, tt.replicas)
+				var out, errOut bytes.Buffer
+
+				code, err := Run(
+					strings.NewReader(in), &out, &errOut,
+					Options{ChartPath: chartPath, RulesFiles: []string{"values.cel.yaml"}, FailOn: tt.failOn},
+				)
+
+				assert.Equal(t, tt.wantCode, code)
+				if tt.wantErr != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+					assert.Empty(t, out.String())
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, in, out.String())
+				assert.Contains(t, errOut.String(), tt.wantErrOut)
+			},
+		)
+	}
+}
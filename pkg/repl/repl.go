@@ -0,0 +1,321 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	prompt = "cel> "
+
+	helpText = `Enter a CEL expression to evaluate it against the loaded values, e.g. values.service.port
+Named expressions can be referenced with ${name} or ${name(args)}.
+
+Commands:
+  :load FILE       Merge an extra values file on top of the loaded values
+  :rules           List the loaded rules
+  :explain RULE    Explain a rule by ID or index (as listed by :rules)
+  :history         Show the expressions entered so far
+  :help            Show this help
+  :quit            Exit the REPL (or press Ctrl-D)`
+)
+
+// valuesPathPattern matches a values path being typed at the end of the line, e.g. "values.service.po"
+var valuesPathPattern = regexp.MustCompile(`values(\.[A-Za-z_][A-Za-z0-9_]*)*\.[A-Za-z0-9_]*$|values$`)
+
+// REPL evaluates expressions interactively against chart values
+type REPL struct {
+	validator    *validator.Validator
+	valuesLoader *validator.ValuesLoader
+	chartPath    string
+	values       map[string]any
+	rules        *models.ValidationRules
+	history      []string
+}
+
+// New creates a REPL over values and rules loaded with the given validator,
+// so expressions are evaluated in the same environment as validation
+func New(v *validator.Validator, chartPath string, values map[string]any, rules *models.ValidationRules) *REPL {
+	if rules == nil {
+		rules = &models.ValidationRules{}
+	}
+	return &REPL{
+		validator:    v,
+		valuesLoader: validator.NewValuesLoader(),
+		chartPath:    chartPath,
+		values:       values,
+		rules:        rules,
+	}
+}
+
+// Run reads lines until EOF or :quit, with line editing, history and tab completion if in is a terminal
+func (r *REPL) Run(in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return r.runLines(in, out)
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %v", err)
+	}
+	defer func() {
+		_ = term.Restore(fd, state)
+	}()
+
+	terminal := term.NewTerminal(
+		struct {
+			io.Reader
+			io.Writer
+		}{in, out},
+		prompt,
+	)
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return r.Complete(line, pos)
+	}
+	if width, height, err := term.GetSize(fd); err == nil {
+		_ = terminal.SetSize(width, height)
+	}
+
+	_, _ = fmt.Fprintln(terminal, "Type :help for help, Ctrl-D to exit")
+	for {
+		line, err := terminal.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.Execute(line, terminal) {
+			return nil
+		}
+	}
+}
+
+// runLines evaluates lines from a non-interactive input, e.g. a pipe
+func (r *REPL) runLines(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if r.Execute(scanner.Text(), out) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Execute handles a single input line and reports whether the REPL should exit
+func (r *REPL) Execute(line string, out io.Writer) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(line, ":") {
+		r.history = append(r.history, line)
+		r.evaluate(line, out)
+		return false
+	}
+
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case ":quit", ":q", ":exit":
+		return true
+	case ":help", ":h":
+		_, _ = fmt.Fprintln(out, helpText)
+	case ":load":
+		r.load(arg, out)
+	case ":rules":
+		r.listRules(out)
+	case ":explain":
+		r.explain(arg, out)
+	case ":history":
+		for i, expr := range r.history {
+			_, _ = fmt.Fprintf(out, "%4d  %s\n", i+1, expr)
+		}
+	default:
+		_, _ = fmt.Fprintf(out, "Unknown command %s, type :help for help\n", command)
+	}
+
+	return false
+}
+
+// evaluate evaluates an expression and prints its value and type
+func (r *REPL) evaluate(expr string, out io.Writer) {
+	output, err := r.validator.Evaluate(expr, r.values, r.rules)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "Error: %v\n", err)
+		return
+	}
+
+	if output.Expanded != "" {
+		_, _ = fmt.Fprintf(out, "Expanded: %s\n", output.Expanded)
+	}
+	switch output.Value.(type) {
+	case nil:
+		_, _ = fmt.Fprintf(out, "null (%s)\n", output.Type)
+	case map[string]any, []any:
+		value, err := yaml.Marshal(output.Value)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "Error: failed to marshal value to YAML: %v\n", err)
+			return
+		}
+		_, _ = fmt.Fprintf(out, "%s(%s)\n", value, output.Type)
+	default:
+		_, _ = fmt.Fprintf(out, "%v (%s)\n", output.Value, output.Type)
+	}
+}
+
+// load merges an extra values file, relative to the chart, on top of the loaded values
+func (r *REPL) load(file string, out io.Writer) {
+	if file == "" {
+		_, _ = fmt.Fprintln(out, "Usage: :load FILE")
+		return
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(r.chartPath, file)
+	}
+
+	values, err := r.valuesLoader.LoadAndMergeValues([]string{file})
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "Error: %v\n", err)
+		return
+	}
+
+	r.values = r.valuesLoader.MergeValues(r.values, values)
+	_, _ = fmt.Fprintf(out, "Loaded %s\n", file)
+}
+
+// listRules prints every loaded rule with its index
+func (r *REPL) listRules(out io.Writer) {
+	if len(r.rules.Rules) == 0 {
+		_, _ = fmt.Fprintln(out, "No rules loaded")
+		return
+	}
+
+	for i, rule := range r.rules.Rules {
+		id := rule.ID
+		if id == "" {
+			id = "-"
+		}
+		severity := rule.Severity
+		if severity == "" {
+			severity = validator.ErrorSeverity
+		}
+		disabled := ""
+		if rule.IsDisabled() {
+			disabled = " (disabled)"
+		}
+		_, _ = fmt.Fprintf(out, "%3d  %-24s %-8s %s%s\n", i, id, severity, rule.Desc, disabled)
+	}
+}
+
+// explain prints how a rule, selected by ID or index, is expanded and evaluated against the loaded values
+func (r *REPL) explain(ref string, out io.Writer) {
+	if ref == "" {
+		_, _ = fmt.Fprintln(out, "Usage: :explain RULE")
+		return
+	}
+
+	rule, ok := validator.FindRule(r.rules, ref)
+	if !ok {
+		_, _ = fmt.Fprintf(out, "Rule %s not found, type :rules to list rules\n", ref)
+		return
+	}
+
+	explanation, err := r.validator.Explain(rule, r.values, r.rules)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "Error: %v\n", err)
+		return
+	}
+	_, _ = fmt.Fprint(out, explanation.String())
+}
+
+// Complete completes the values path before the cursor to the longest common prefix of the matching keys
+func (r *REPL) Complete(line string, pos int) (string, int, bool) {
+	before, after := line[:pos], line[pos:]
+
+	loc := valuesPathPattern.FindStringIndex(before)
+	if loc == nil || (loc[0] > 0 && isIdentChar(before[loc[0]-1])) {
+		return "", 0, false
+	}
+
+	path := before[loc[0]:]
+	if path == "values" {
+		path += "."
+	}
+	parts := strings.Split(path, ".")
+	parent, prefix := parts[1:len(parts)-1], parts[len(parts)-1]
+
+	current := r.values
+	for _, part := range parent {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return "", 0, false
+		}
+		current = next
+	}
+
+	candidates := make([]string, 0)
+	for key := range current {
+		if strings.HasPrefix(key, prefix) && isIdentifier(key) {
+			candidates = append(candidates, key)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(candidates)
+
+	completion := longestCommonPrefix(candidates)
+	if len(candidates) == 1 {
+		if _, isMap := current[completion].(map[string]any); isMap {
+			completion += "."
+		}
+	}
+
+	completed := before[:loc[0]] + strings.Join(parts[:len(parts)-1], ".") + "." + completion
+	return completed + after, len(completed), true
+}
+
+func longestCommonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,202 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestREPL(t *testing.T) *REPL {
+	t.Helper()
+
+	values := map[string]any{
+		"replicas": 1,
+		"service": map[string]any{
+			"port":     8080,
+			"portName": "http",
+			"type":     "ClusterIP",
+		},
+		"serviceAccount": map[string]any{
+			"create": true,
+		},
+		"node-selector": map[string]any{},
+	}
+	rules := &models.ValidationRules{
+		Rules: []models.Rule{
+			{ID: "replicas-ha", Expr: "values.replicas >= 2", Desc: "replicas should be highly available", Severity: "warning"},
+			{Expr: "has(values.service)", Desc: "service is required"},
+			{
+				ID:    "deployment-ha",
+				Expr:  "object.spec.replicas >= 2",
+				Desc:  "deployments should be highly available",
+				Match: &models.Match{Kinds: []string{"Deployment"}},
+			},
+		},
+		Expressions: map[string]string{"minReplicas": "values.replicas >= $0"},
+	}
+
+	return New(validator.New(), t.TempDir(), values, rules)
+}
+
+func TestREPL_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+		quit     bool
+	}{
+		{
+			name:     "expression",
+			line:     "values.service.port + 1",
+			expected: "8081 (int)\n",
+		},
+		{
+			name:     "named expression",
+			line:     "${minReplicas(1)}",
+			expected: "Expanded: (values.replicas >= 1)\ntrue (bool)\n",
+		},
+		{
+			name:     "invalid expression",
+			line:     "values.replicas >>",
+			expected: "Error: invalid expression",
+		},
+		{
+			name:     "list rules",
+			line:     ":rules",
+			expected: "  0  replicas-ha              warning  replicas should be highly available\n  1  -                        error    service is required\n  2  deployment-ha            error    deployments should be highly available\n",
+		},
+		{
+			name:     "explain by id",
+			line:     ":explain replicas-ha",
+			expected: "ID: replicas-ha\nDescription: replicas should be highly available\nSeverity: warning\nExpression: values.replicas >= 2\n\nValues:\n  values.replicas = 1\n\nResult: ❌ false\n",
+		},
+		{
+			name:     "explain by index",
+			line:     ":explain 1",
+			expected: "Description: service is required\nSeverity: error\nExpression: has(values.service)\n",
+		},
+		{
+			name:     "explain match rule",
+			line:     ":explain deployment-ha",
+			expected: "Error: rule 'deployments should be highly available' applies to rendered manifests and cannot be explained against values\n",
+		},
+		{
+			name:     "explain unknown rule",
+			line:     ":explain missing",
+			expected: "Rule missing not found",
+		},
+		{
+			name:     "unknown command",
+			line:     ":bogus",
+			expected: "Unknown command :bogus",
+		},
+		{
+			name: "quit",
+			line: ":quit",
+			quit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				r := newTestREPL(t)
+				var out bytes.Buffer
+
+				quit := r.Execute(tt.line, &out)
+
+				assert.Equal(t, tt.quit, quit)
+				assert.Contains(t, out.String(), tt.expected)
+			},
+		)
+	}
+}
+
+func TestREPL_Load(t *testing.T) {
+	r := newTestREPL(t)
+	require.NoError(t, os.WriteFile(filepath.Join(r.chartPath, "prod.yaml"), []byte("replicas: 3"), 0644))
+
+	var out bytes.Buffer
+	r.Execute(":load prod.yaml", &out)
+	r.Execute("values.replicas", &out)
+	r.Execute("values.service.type", &out)
+	r.Execute(":history", &out)
+
+	assert.Contains(t, out.String(), "Loaded "+filepath.Join(r.chartPath, "prod.yaml")+"\n")
+	assert.Contains(t, out.String(), "3 (int)\nClusterIP (string)\n")
+	assert.Contains(t, out.String(), "   1  values.replicas\n   2  values.service.type\n")
+}
+
+func TestREPL_Complete(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		pos     int
+		want    string
+		wantPos int
+		wantOk  bool
+	}{
+		{
+			name:    "common prefix of top-level keys",
+			line:    "values.ser",
+			want:    "values.service",
+			wantPos: 14,
+			wantOk:  true,
+		},
+		{
+			name:    "single map key adds dot",
+			line:    "values.serviceA",
+			want:    "values.serviceAccount.",
+			wantPos: 22,
+			wantOk:  true,
+		},
+		{
+			name:    "nested key inside expression",
+			line:    "has(values.service.ty) && true",
+			pos:     21,
+			want:    "has(values.service.type) && true",
+			wantPos: 23,
+			wantOk:  true,
+		},
+		{
+			name:    "bare values",
+			line:    "values",
+			want:    "values.",
+			wantPos: 7,
+			wantOk:  true,
+		},
+		{
+			name: "unknown parent",
+			line: "values.missing.po",
+		},
+		{
+			name: "not a values path",
+			line: "myvalues.ser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pos := tt.pos
+				if pos == 0 {
+					pos = len(tt.line)
+				}
+
+				got, gotPos, ok := newTestREPL(t).Complete(tt.line, pos)
+
+				assert.Equal(t, tt.wantOk, ok)
+				if tt.wantOk {
+					assert.Equal(t, tt.want, got)
+					assert.Equal(t, tt.wantPos, gotPos)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,242 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/parser"
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+)
+
+// Coverage collects rule and ternary branch outcomes from rule evaluations
+type Coverage struct {
+	mu    sync.Mutex
+	rules map[int]*RuleCoverage
+}
+
+// CoverageReport is the machine-readable coverage result
+type CoverageReport struct {
+	Summary CoverageSummary `json:"summary"`
+	Rules   []*RuleCoverage `json:"rules"`
+}
+
+// CoverageSummary contains the totals of a coverage report
+type CoverageSummary struct {
+	Rules           int `json:"rules"`
+	RulesCovered    int `json:"rules_covered"`
+	Branches        int `json:"branches"`
+	BranchesCovered int `json:"branches_covered"`
+}
+
+// RuleCoverage records how often a rule evaluated to true and false, evaluation errors count as false
+type RuleCoverage struct {
+	ID          string            `json:"id,omitempty"`
+	Description string            `json:"description"`
+	Expression  string            `json:"expression"`
+	Evaluations int               `json:"evaluations"`
+	True        int               `json:"true"`
+	False       int               `json:"false"`
+	Covered     bool              `json:"covered"`
+	Branches    []*BranchCoverage `json:"branches,omitempty"`
+}
+
+// BranchCoverage records how often the condition of a ternary evaluated to true and false
+type BranchCoverage struct {
+	Condition string `json:"condition"`
+	Column    int    `json:"column"` // 1-based column of the ternary operator
+	True      int    `json:"true"`
+	False     int    `json:"false"`
+	Covered   bool   `json:"covered"`
+
+	conditionID int64
+}
+
+// NewCoverage creates an empty coverage collector
+func NewCoverage() *Coverage {
+	return &Coverage{
+		rules: make(map[int]*RuleCoverage),
+	}
+}
+
+// Observe records a rule evaluation, it is meant to be registered with validator.WithEvalObserver
+func (c *Coverage) Observe(e *validator.Evaluation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rc, ok := c.rules[e.Index]
+	if !ok {
+		rc = newRuleCoverage(e)
+		c.rules[e.Index] = rc
+	}
+
+	rc.Evaluations++
+	if e.Err == nil && e.Result.Value() == true {
+		rc.True++
+	} else {
+		rc.False++
+	}
+
+	if e.Details == nil || e.Details.State() == nil {
+		return
+	}
+	for _, branch := range rc.Branches {
+		value, found := e.Details.State().Value(branch.conditionID)
+		if !found {
+			continue
+		}
+		switch value {
+		case types.True:
+			branch.True++
+		case types.False:
+			branch.False++
+		}
+	}
+}
+
+// newRuleCoverage creates the coverage entry of a rule, discovering the ternaries of its expression
+func newRuleCoverage(e *validator.Evaluation) *RuleCoverage {
+	rc := &RuleCoverage{
+		ID:          e.Rule.ID,
+		Description: e.Rule.Desc,
+		Expression:  e.Rule.Expr,
+	}
+	if e.AST == nil {
+		return rc
+	}
+
+	native := e.AST.NativeRep()
+	ternaries := ast.MatchDescendants(ast.NavigateAST(native), ast.FunctionMatcher(operators.Conditional))
+	sort.Slice(
+		ternaries, func(i, j int) bool {
+			return ternaries[i].ID() < ternaries[j].ID()
+		},
+	)
+	for _, ternary := range ternaries {
+		condition := ternary.AsCall().Args()[0]
+		text, err := parser.Unparse(condition, native.SourceInfo())
+		if err != nil {
+			text = fmt.Sprintf("<condition #%d>", condition.ID())
+		}
+		rc.Branches = append(
+			rc.Branches, &BranchCoverage{
+				Condition:   text,
+				Column:      native.SourceInfo().GetStartLocation(ternary.ID()).Column() + 1,
+				conditionID: condition.ID(),
+			},
+		)
+	}
+
+	return rc
+}
+
+// Report returns the coverage of all enabled rules, including rules that were never evaluated
+func (c *Coverage) Report(rules *models.ValidationRules) *CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &CoverageReport{Rules: make([]*RuleCoverage, 0)}
+	for i, rule := range rules.Rules {
+		if rule.IsDisabled() {
+			continue
+		}
+
+		rc, ok := c.rules[i]
+		if !ok {
+			rc = &RuleCoverage{ID: rule.ID, Description: rule.Desc, Expression: rule.Expr}
+		}
+
+		rc.Covered = rc.True > 0 && rc.False > 0
+		report.Summary.Rules++
+		if rc.Covered {
+			report.Summary.RulesCovered++
+		}
+
+		for _, branch := range rc.Branches {
+			branch.Covered = branch.True > 0 && branch.False > 0
+			report.Summary.Branches++
+			if branch.Covered {
+				report.Summary.BranchesCovered++
+			}
+		}
+
+		report.Rules = append(report.Rules, rc)
+	}
+
+	return report
+}
+
+// WriteJSON writes the coverage report as indented JSON
+func (r *CoverageReport) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage to JSON: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// WriteText writes a human-readable coverage summary
+func (r *CoverageReport) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(
+		w,
+		"Coverage: %d/%d rules covered (%s), %d/%d branches covered (%s)\n",
+		r.Summary.RulesCovered,
+		r.Summary.Rules,
+		percent(r.Summary.RulesCovered, r.Summary.Rules),
+		r.Summary.BranchesCovered,
+		r.Summary.Branches,
+		percent(r.Summary.BranchesCovered, r.Summary.Branches),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, rc := range r.Rules {
+		symbol := "✅"
+		if !rc.Covered {
+			symbol = "❌"
+		}
+		name := rc.ID
+		if name == "" {
+			name = rc.Description
+		}
+		if _, err := fmt.Fprintf(w, "  %s %s (true: %d, false: %d)\n", symbol, name, rc.True, rc.False); err != nil {
+			return err
+		}
+
+		for _, branch := range rc.Branches {
+			symbol := "✅"
+			if !branch.Covered {
+				symbol = "❌"
+			}
+			_, err := fmt.Fprintf(
+				w,
+				"     %s branch at column %d: %s (true: %d, false: %d)\n",
+				symbol,
+				branch.Column,
+				branch.Condition,
+				branch.True,
+				branch.False,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func percent(covered, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", float64(covered)*100/float64(total))
+}
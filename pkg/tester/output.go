@@ -0,0 +1,88 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/idsulik/helm-cel/pkg/junit"
+)
+
+// WriteText writes a human-readable summary of the report
+func WriteText(w io.Writer, report *Report, chartPath string) error {
+	passed, failed := 0, 0
+
+	for _, suite := range report.Suites {
+		if _, err := fmt.Fprintln(w, relativePath(chartPath, suite.File)); err != nil {
+			return err
+		}
+		for _, tc := range suite.Cases {
+			if len(tc.Failures) == 0 {
+				passed++
+				if _, err := fmt.Fprintf(w, "  ✅ %s\n", tc.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			failed++
+			if _, err := fmt.Fprintf(w, "  ❌ %s\n", tc.Name); err != nil {
+				return err
+			}
+			for _, failure := range tc.Failures {
+				if _, err := fmt.Fprintf(w, "     %s\n", failure); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Tests: %d passed, %d failed, %d total\n", passed, failed, passed+failed)
+	return err
+}
+
+// WriteJUnit writes the report as JUnit XML with one test suite per test file
+func WriteJUnit(w io.Writer, report *Report, chartPath string) error {
+	suites := &junit.TestSuites{Name: "helm-cel"}
+
+	for _, suite := range report.Suites {
+		name := relativePath(chartPath, suite.File)
+		junitSuite := junit.TestSuite{
+			Name: name,
+			Time: suite.Duration.Seconds(),
+		}
+
+		for _, tc := range suite.Cases {
+			junitCase := junit.TestCase{
+				Name:      tc.Name,
+				Classname: name,
+				Time:      tc.Duration.Seconds(),
+			}
+			if len(tc.Failures) > 0 {
+				junitSuite.Failures++
+				junitCase.Failure = &junit.Failure{
+					Message: tc.Failures[0],
+					Body:    strings.Join(tc.Failures, "\n"),
+				}
+			}
+			junitSuite.Tests++
+			junitSuite.Cases = append(junitSuite.Cases, junitCase)
+		}
+
+		suites.Add(junitSuite)
+	}
+
+	return suites.Write(w)
+}
+
+// relativePath returns path relative to base, or path unchanged if that is not possible
+func relativePath(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,254 @@
+package tester
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a test file with test cases for the rules of a chart
+type Suite struct {
+	Tests []TestCase `yaml:"tests"`
+}
+
+// TestCase describes fixture values and the rules expected to pass or fail against them
+type TestCase struct {
+	Name        string         `yaml:"name"`
+	ValuesFiles []string       `yaml:"valuesFiles,omitempty"` // relative to the test file
+	Values      map[string]any `yaml:"values,omitempty"`      // merged on top of the values files
+	Expect      Expectation    `yaml:"expect"`
+}
+
+// Expectation lists rule IDs that must pass or fail
+type Expectation struct {
+	Pass []string `yaml:"pass,omitempty"`
+	Fail []string `yaml:"fail,omitempty"`
+}
+
+// Report contains the results of all test files
+type Report struct {
+	Suites []*SuiteResult
+}
+
+// SuiteResult contains the results of a single test file
+type SuiteResult struct {
+	File     string
+	Cases    []*CaseResult
+	Duration time.Duration
+}
+
+// CaseResult contains the result of a single test case, failed if it has any failures
+type CaseResult struct {
+	Name     string
+	Failures []string
+	Duration time.Duration
+}
+
+// Runner runs test files against the rules of a chart
+type Runner struct {
+	validator    *validator.Validator
+	valuesLoader *validator.ValuesLoader
+}
+
+// New creates a Runner that evaluates test cases with the given validator
+func New(v *validator.Validator) *Runner {
+	return &Runner{
+		validator:    v,
+		valuesLoader: validator.NewValuesLoader(),
+	}
+}
+
+// FindTestFiles returns the sorted test files matching the glob patterns, relative to the chart path
+func FindTestFiles(chartPath string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(chartPath, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid test file pattern %s: %v", pattern, err)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no test files found matching %v", patterns)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// Run runs every test case of the test files against the prepared rules
+func (r *Runner) Run(testFiles []string, rules *models.ValidationRules) (*Report, error) {
+	report := &Report{}
+
+	for _, file := range testFiles {
+		suite, err := r.loadSuite(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tests from %s: %v", file, err)
+		}
+
+		start := time.Now()
+		suiteResult := &SuiteResult{File: file}
+		for i, tc := range suite.Tests {
+			suiteResult.Cases = append(suiteResult.Cases, r.runCase(filepath.Dir(file), i, tc, rules))
+		}
+		suiteResult.Duration = time.Since(start)
+
+		report.Suites = append(report.Suites, suiteResult)
+	}
+
+	return report, nil
+}
+
+// loadSuite loads a single test file
+func (r *Runner) loadSuite(path string) (*Suite, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file: %v", err)
+	}
+
+	var suite Suite
+	if err := yaml.Unmarshal(content, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test file: %v", err)
+	}
+
+	return &suite, nil
+}
+
+// runCase validates the fixture values of a test case and compares the outcome with its expectations
+func (r *Runner) runCase(baseDir string, index int, tc TestCase, rules *models.ValidationRules) *CaseResult {
+	start := time.Now()
+	caseResult := &CaseResult{Name: tc.Name}
+	if caseResult.Name == "" {
+		caseResult.Name = fmt.Sprintf("test #%d", index+1)
+	}
+	defer func() {
+		caseResult.Duration = time.Since(start)
+	}()
+
+	values, err := r.validator.LoadChartValues(baseDir, tc.ValuesFiles)
+	if err != nil {
+		caseResult.Failures = append(caseResult.Failures, err.Error())
+		return caseResult
+	}
+	values = r.valuesLoader.MergeValues(values, tc.Values)
+
+	result, err := r.validator.Validate(values, rules)
+	if err != nil {
+		caseResult.Failures = append(caseResult.Failures, err.Error())
+		return caseResult
+	}
+
+	failed := failedRules(result)
+	passed := passedRules(result)
+	for _, id := range tc.Expect.Fail {
+		switch msg := checkRule(id, rules, failed[id]); {
+		case msg != "":
+			caseResult.Failures = append(caseResult.Failures, msg)
+		case failed[id] != nil:
+		case passed[id]:
+			caseResult.Failures = append(caseResult.Failures, fmt.Sprintf("expected rule '%s' to fail, but it passed", id))
+		default:
+			caseResult.Failures = append(caseResult.Failures, notEvaluated("fail", id, result))
+		}
+	}
+	for _, id := range tc.Expect.Pass {
+		msg := checkRule(id, rules, failed[id])
+		switch validationError := failed[id]; {
+		case msg != "":
+			caseResult.Failures = append(caseResult.Failures, msg)
+		case validationError != nil:
+			caseResult.Failures = append(
+				caseResult.Failures,
+				fmt.Sprintf(
+					"expected rule '%s' to pass, but it failed: %s (path: %s, value: %v)",
+					id,
+					validationError.Description,
+					validationError.Path,
+					validationError.Value,
+				),
+			)
+		case !passed[id]:
+			caseResult.Failures = append(caseResult.Failures, notEvaluated("pass", id, result))
+		}
+	}
+
+	return caseResult
+}
+
+// failedRules indexes the failed rules of a validation result by rule ID, regardless of severity
+func failedRules(result *models.ValidationResult) map[string]*models.ValidationError {
+	failed := make(map[string]*models.ValidationError)
+	for _, list := range [][]*models.ValidationError{result.Errors, result.Warnings, result.Infos} {
+		for _, validationError := range list {
+			if validationError.RuleID != "" {
+				failed[validationError.RuleID] = validationError
+			}
+		}
+	}
+	return failed
+}
+
+// passedRules returns the IDs of the rules that passed
+func passedRules(result *models.ValidationResult) map[string]bool {
+	passed := make(map[string]bool)
+	for _, pass := range result.Passed {
+		if pass.RuleID != "" {
+			passed[pass.RuleID] = true
+		}
+	}
+	return passed
+}
+
+// notEvaluated describes an expectation on a rule that was neither passed nor failed, with the skip reason if known
+func notEvaluated(outcome, id string, result *models.ValidationResult) string {
+	msg := fmt.Sprintf("expected rule '%s' to %s, but the rule was not evaluated", id, outcome)
+	for _, skip := range result.Skipped {
+		if skip.RuleID == id {
+			return fmt.Sprintf("%s (%s)", msg, skip.Reason)
+		}
+	}
+	return msg
+}
+
+// checkRule returns a failure message if the rule ID cannot be evaluated, a rule that does not compile never
+// satisfies an expectation
+func checkRule(id string, rules *models.ValidationRules, validationError *models.ValidationError) string {
+	for _, rule := range rules.Rules {
+		if rule.ID == id {
+			if rule.IsDisabled() {
+				return fmt.Sprintf("rule '%s' is disabled", id)
+			}
+			if validationError != nil && validationError.Invalid {
+				return fmt.Sprintf("rule '%s' does not compile: %s", id, validationError.Description)
+			}
+			return ""
+		}
+	}
+	return fmt.Sprintf("rule '%s' not found", id)
+}
+
+// Failed reports whether any test case failed
+func (r *Report) Failed() bool {
+	for _, suite := range r.Suites {
+		for _, tc := range suite.Cases {
+			if len(tc.Failures) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
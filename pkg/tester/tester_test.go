@@ -0,0 +1,239 @@
+package tester
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  - id: image-tag-pinned
+    expr: 'values.image.tag != "latest"'
+    desc: "image tag must be pinned"
+  - id: replicas-ha
+    expr: "values.replicas >= 2"
+    desc: "replicas should be highly available"
+    severity: warning
+  - id: debug-disabled
+    expr: "!values.debug"
+    desc: "debug must be disabled"
+    disabled: true`
+
+func TestRunner_Run(t *testing.T) {
+	chartDir := t.TempDir()
+	writeFile(t, chartDir, "values.yaml", "image:\n  tag: \"1.0\"\nreplicas: 2\ndebug: false")
+	writeFile(t, chartDir, "values.cel.yaml", testRules)
+	writeFile(t, chartDir, "tests/fixtures/latest.yaml", "image:\n  tag: latest")
+	writeFile(
+		t, chartDir, "tests/image.celtest.yaml", `
+tests:
+  - name: rejects latest tag
+    valuesFiles: [../values.yaml, fixtures/latest.yaml]
+    expect:
+      fail: [image-tag-pinned]
+      pass: [replicas-ha]
+  - name: inline values
+    valuesFiles: [../values.yaml]
+    values:
+      replicas: 1
+    expect:
+      fail: [replicas-ha]
+  - valuesFiles: [../values.yaml]
+    expect:
+      fail: [image-tag-pinned]
+      pass: [unknown, debug-disabled]`,
+	)
+
+	v := validator.New()
+	rules, err := v.LoadChartRules(chartDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	files, err := FindTestFiles(chartDir, []string{"tests/*.celtest.yaml"})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	report, err := New(v).Run(files, rules)
+	require.NoError(t, err)
+	require.Len(t, report.Suites, 1)
+
+	cases := report.Suites[0].Cases
+	require.Len(t, cases, 3)
+	assert.Empty(t, cases[0].Failures)
+	assert.Empty(t, cases[1].Failures)
+	assert.Equal(t, "test #3", cases[2].Name)
+	assert.Equal(
+		t, []string{
+			"expected rule 'image-tag-pinned' to fail, but it passed",
+			"rule 'unknown' not found",
+			"rule 'debug-disabled' is disabled",
+		}, cases[2].Failures,
+	)
+	assert.True(t, report.Failed())
+
+	t.Run(
+		"text output", func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteText(&buf, report, chartDir))
+			assert.Contains(t, buf.String(), "tests/image.celtest.yaml\n  ✅ rejects latest tag\n")
+			assert.Contains(t, buf.String(), "Tests: 2 passed, 1 failed, 3 total\n")
+		},
+	)
+
+	t.Run(
+		"junit output", func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteJUnit(&buf, report, chartDir))
+			assert.Contains(t, buf.String(), `<testsuite name="tests/image.celtest.yaml" tests="3" failures="1"`)
+			assert.Contains(t, buf.String(), `<failure message="expected rule &#39;image-tag-pinned&#39; to fail, but it passed">`)
+		},
+	)
+}
+
+func TestRunner_Run_NotEvaluated(t *testing.T) {
+	chartDir := t.TempDir()
+	writeFile(t, chartDir, "values.yaml", "replicas: 2")
+	writeFile(
+		t, chartDir, "values.cel.yaml", `
+rules:
+  - id: resource-limits
+    match:
+      kinds: [Deployment]
+    expr: "has(object.spec)"
+    desc: "deployments must have a spec"`,
+	)
+	writeFile(
+		t, chartDir, "tests/manifests.celtest.yaml", `
+tests:
+  - name: manifest rules
+    valuesFiles: [../values.yaml]
+    expect:
+      pass: [resource-limits]
+      fail: [resource-limits]`,
+	)
+
+	v := validator.New()
+	rules, err := v.LoadChartRules(chartDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	report, err := New(v).Run([]string{filepath.Join(chartDir, "tests/manifests.celtest.yaml")}, rules)
+	require.NoError(t, err)
+	assert.Equal(
+		t, []string{
+			"expected rule 'resource-limits' to fail, but the rule was not evaluated (no rendered manifests)",
+			"expected rule 'resource-limits' to pass, but the rule was not evaluated (no rendered manifests)",
+		}, report.Suites[0].Cases[0].Failures,
+	)
+}
+
+func TestRunner_Run_InvalidRule(t *testing.T) {
+	chartDir := t.TempDir()
+	writeFile(t, chartDir, "values.yaml", "replicas: 2")
+	writeFile(
+		t, chartDir, "values.cel.yaml", `
+rules:
+  - id: replicas-ha
+    expr: "values.replicas >="
+    desc: "replicas should be highly available"`,
+	)
+	writeFile(
+		t, chartDir, "tests/invalid.celtest.yaml", `
+tests:
+  - name: broken rule
+    valuesFiles: [../values.yaml]
+    expect:
+      fail: [replicas-ha]`,
+	)
+
+	v := validator.New()
+	rules, err := v.LoadChartRules(chartDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	report, err := New(v).Run([]string{filepath.Join(chartDir, "tests/invalid.celtest.yaml")}, rules)
+	require.NoError(t, err)
+	failures := report.Suites[0].Cases[0].Failures
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0], "rule 'replicas-ha' does not compile: Invalid rule syntax in 'replicas should be highly available'")
+	assert.True(t, report.Failed())
+}
+
+func TestCoverage(t *testing.T) {
+	chartDir := t.TempDir()
+	writeFile(
+		t, chartDir, "values.cel.yaml", `
+rules:
+  - id: replicas-ha
+    expr: "values.replicas >= 2"
+    desc: "replicas should be highly available"
+  - id: node-port
+    expr: 'values.service.type == "NodePort" ? values.service.nodePort >= 30000 : true'
+    desc: "nodePort must be in range"`,
+	)
+	writeFile(
+		t, chartDir, "tests/rules.celtest.yaml", `
+tests:
+  - name: valid
+    values:
+      replicas: 2
+      service: {type: ClusterIP}
+    expect:
+      pass: [replicas-ha, node-port]
+  - name: single replica
+    values:
+      replicas: 1
+      service: {type: ClusterIP}
+    expect:
+      fail: [replicas-ha]`,
+	)
+
+	coverage := NewCoverage()
+	v := validator.New(validator.WithEvalObserver(coverage.Observe))
+	rules, err := v.LoadChartRules(chartDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	files, err := FindTestFiles(chartDir, []string{"tests/*.celtest.yaml"})
+	require.NoError(t, err)
+	report, err := New(v).Run(files, rules)
+	require.NoError(t, err)
+	require.False(t, report.Failed())
+
+	coverageReport := coverage.Report(rules)
+	assert.Equal(
+		t, CoverageSummary{Rules: 2, RulesCovered: 1, Branches: 1, BranchesCovered: 0}, coverageReport.Summary,
+	)
+
+	require.Len(t, coverageReport.Rules, 2)
+	assert.True(t, coverageReport.Rules[0].Covered)
+	assert.Equal(t, 2, coverageReport.Rules[1].True)
+	assert.Equal(t, 0, coverageReport.Rules[1].False)
+
+	require.Len(t, coverageReport.Rules[1].Branches, 1)
+	branch := coverageReport.Rules[1].Branches[0]
+	assert.Equal(t, `values.service.type == "NodePort"`, branch.Condition)
+	assert.Equal(t, 0, branch.True)
+	assert.Equal(t, 2, branch.False)
+
+	var buf bytes.Buffer
+	require.NoError(t, coverageReport.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Coverage: 1/2 rules covered (50.0%), 0/1 branches covered (0.0%)\n")
+}
+
+func TestFindTestFiles_NoMatches(t *testing.T) {
+	_, err := FindTestFiles(t.TempDir(), []string{"tests/*.celtest.yaml"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no test files found")
+}
+
+// Helper function to write test files, creating parent directories
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
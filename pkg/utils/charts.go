@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindCharts returns the chart directories under root, containing a Chart.yaml and one of the rules files, in lexical
+// order. Hidden directories and the directories of a chart, including its subcharts, are not searched.
+func FindCharts(root string, rulesFiles []string) ([]string, error) {
+	charts := make([]string, 0)
+	err := filepath.WalkDir(
+		root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "Chart.yaml")); err != nil {
+				return nil
+			}
+
+			for _, rulesFile := range rulesFiles {
+				if _, err := os.Stat(filepath.Join(path, rulesFile)); err == nil {
+					charts = append(charts, path)
+					break
+				}
+			}
+			return filepath.SkipDir
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return charts, nil
+}
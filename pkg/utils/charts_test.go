@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCharts(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"charts/api/Chart.yaml",
+		"charts/api/values.cel.yaml",
+		"charts/api/charts/db/Chart.yaml",
+		"charts/api/charts/db/values.cel.yaml",
+		"charts/web/Chart.yaml",
+		"charts/web/rules/web.cel.yaml",
+		"charts/worker/Chart.yaml",
+		"libs/common/Chart.yaml",
+		"libs/common/values.cel.yaml",
+		".cache/old/Chart.yaml",
+		".cache/old/values.cel.yaml",
+	}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	charts, err := FindCharts(root, []string{"values.cel.yaml", "rules/web.cel.yaml"})
+	require.NoError(t, err)
+	assert.Equal(
+		t, []string{
+			filepath.Join(root, "charts/api"),
+			filepath.Join(root, "charts/web"),
+			filepath.Join(root, "libs/common"),
+		}, charts,
+	)
+
+	charts, err = FindCharts(filepath.Join(root, "charts/worker"), []string{"values.cel.yaml"})
+	require.NoError(t, err)
+	assert.Empty(t, charts)
+
+	_, err = FindCharts(filepath.Join(root, "missing"), []string{"values.cel.yaml"})
+	assert.Error(t, err)
+}
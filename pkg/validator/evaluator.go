@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// Evaluate expands the named expressions of the rules in expr and evaluates it against the values,
+// using the same environment as rule validation
+func (v *Validator) Evaluate(expr string, values map[string]any, rules *models.ValidationRules) (*models.EvalOutput, error) {
+	var expressions map[string]string
+	if rules != nil {
+		expressions = rules.Expressions
+	}
+
+	expanded, err := v.exprProcessor.expandExpression(expr, expressions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand expression: %v", err)
+	}
+
+	if v.env == nil {
+		env, err := v.initCelEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+		}
+		v.env = env
+	}
+
+	ast, issues := v.env.Compile(expanded)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %v", issues.Err())
+	}
+
+	program, err := v.env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process expression: %v", err)
+	}
+
+	out, _, err := program.Eval(map[string]any{"values": values})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression: %v", err)
+	}
+
+	output := &models.EvalOutput{
+		Expression: expr,
+		Value:      nativeValue(out),
+		Type:       out.Type().TypeName(),
+	}
+	if expanded != expr {
+		output.Expanded = expanded
+	}
+
+	return output, nil
+}
+
+// nativeValue converts a CEL value into a plain Go value suitable for printing and marshaling, converting lists and
+// maps element by element so integers keep their type and precision
+func nativeValue(val ref.Val) any {
+	switch val.Type() {
+	case types.ListType:
+		list := val.(traits.Lister)
+		size := int(list.Size().(types.Int))
+		native := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			native = append(native, nativeValue(list.Get(types.Int(i))))
+		}
+		return native
+	case types.MapType:
+		mapper := val.(traits.Mapper)
+		native := make(map[string]any)
+		for it := mapper.Iterator(); it.HasNext() == types.True; {
+			key := it.Next()
+			name, ok := key.Value().(string)
+			if !ok {
+				name = fmt.Sprintf("%v", nativeValue(key))
+			}
+			native[name] = nativeValue(mapper.Get(key))
+		}
+		return native
+	case types.NullType:
+		return nil
+	case types.TypeType:
+		return val.(ref.Type).TypeName()
+	default:
+		return val.Value()
+	}
+}
@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Evaluate(t *testing.T) {
+	values := map[string]any{
+		"replicas": 3,
+		"ids":      []any{9007199254740993},
+		"service": map[string]any{
+			"port": 8080,
+			"type": "ClusterIP",
+		},
+	}
+	rules := &models.ValidationRules{
+		Expressions: map[string]string{
+			"minReplicas": "values.replicas >= $0",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    *models.EvalOutput
+		wantErr string
+	}{
+		{
+			name: "scalar value",
+			expr: "values.service.port",
+			want: &models.EvalOutput{Expression: "values.service.port", Value: int64(8080), Type: "int"},
+		},
+		{
+			name: "map value",
+			expr: "values.service",
+			want: &models.EvalOutput{
+				Expression: "values.service",
+				Value:      map[string]any{"port": int64(8080), "type": "ClusterIP"},
+				Type:       "map",
+			},
+		},
+		{
+			name: "large integers in a list",
+			expr: "values.ids",
+			want: &models.EvalOutput{
+				Expression: "values.ids",
+				Value:      []any{int64(9007199254740993)},
+				Type:       "list",
+			},
+		},
+		{
+			name: "list built in CEL",
+			expr: "[values.replicas, 1].map(x, x * 2)",
+			want: &models.EvalOutput{
+				Expression: "[values.replicas, 1].map(x, x * 2)",
+				Value:      []any{int64(6), int64(2)},
+				Type:       "list",
+			},
+		},
+		{
+			name: "named expression",
+			expr: "${minReplicas(2)}",
+			want: &models.EvalOutput{
+				Expression: "${minReplicas(2)}",
+				Expanded:   "(values.replicas >= 2)",
+				Value:      true,
+				Type:       "bool",
+			},
+		},
+		{
+			name:    "undefined reference",
+			expr:    "${missing}",
+			wantErr: "failed to expand expression",
+		},
+		{
+			name:    "invalid syntax",
+			expr:    "values.replicas >>",
+			wantErr: "invalid expression",
+		},
+		{
+			name:    "missing key",
+			expr:    "values.ingress.enabled",
+			wantErr: "no such key: ingress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := New().Evaluate(tt.expr, values, rules)
+				if tt.wantErr != "" {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			},
+		)
+	}
+}
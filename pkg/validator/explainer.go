@@ -0,0 +1,241 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/parser"
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// FindRule looks up a rule by ID, falling back to its index in the merged rules
+func FindRule(rules *models.ValidationRules, ref string) (models.Rule, bool) {
+	for _, rule := range rules.Rules {
+		if rule.ID == ref {
+			return rule, true
+		}
+	}
+	if i, err := strconv.Atoi(ref); err == nil && i >= 0 && i < len(rules.Rules) {
+		return rules.Rules[i], true
+	}
+	return models.Rule{}, false
+}
+
+// Explain evaluates a rule prepared by LoadChartRules against the values, recording each named expression
+// substitution, the values paths the rule references and the result of every subexpression.
+// Rules with a match block are evaluated against rendered manifests and are refused
+func (v *Validator) Explain(rule models.Rule, values map[string]any, rules *models.ValidationRules) (*models.Explanation, error) {
+	if rule.Match != nil {
+		return nil, fmt.Errorf("rule '%s' applies to rendered manifests and cannot be explained against values", rule.Desc)
+	}
+
+	source := rule.Source
+	if source == "" {
+		source = rule.Expr
+	}
+	severity := rule.Severity
+	if severity == "" {
+		severity = ErrorSeverity
+	}
+
+	explanation := &models.Explanation{
+		ID:          rule.ID,
+		Description: rule.Desc,
+		Severity:    severity,
+		Expression:  source,
+	}
+
+	expanded, err := v.exprProcessor.expandExpressionSteps(source, rules.Expressions, &explanation.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand rule '%s': %v", rule.Desc, err)
+	}
+	explanation.Expanded = expanded
+
+	if v.env == nil {
+		env, err := v.initCelEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+		}
+		v.env = env
+	}
+
+	// Macro calls are tracked so that subexpressions using has(), all(), exists()... can be printed back
+	env, err := v.env.Extend(cel.EnableMacroCallTracking())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+	}
+
+	compiled, issues := env.Compile(expanded)
+	if issues != nil && issues.Err() != nil {
+		explanation.Error = fmt.Sprintf("invalid rule syntax: %v", issues.Err())
+		return explanation, nil
+	}
+
+	// Exhaustive evaluation disables short-circuits, so both sides of logical operators and ternaries are shown
+	program, err := env.Program(compiled, cel.EvalOptions(cel.OptExhaustiveEval))
+	if err != nil {
+		explanation.Error = fmt.Sprintf("failed to process rule: %v", err)
+		return explanation, nil
+	}
+
+	out, details, err := program.Eval(map[string]any{"values": values})
+	if err != nil {
+		explanation.Error = err.Error()
+	} else {
+		explanation.Result = nativeValue(out)
+		explanation.Passed = out.Value() == true
+	}
+
+	native := compiled.NativeRep()
+	w := &explainWalker{root: native.Expr().ID(), seen: make(map[string]bool)}
+	w.walk(native.Expr(), false)
+
+	for _, path := range w.paths {
+		value, found := LookupValue(values, path)
+		explanation.Values = append(explanation.Values, models.ValueReference{Path: path, Value: value, Found: found})
+	}
+
+	if details == nil || details.State() == nil {
+		return explanation, nil
+	}
+	for _, e := range w.subexpressions {
+		val, found := details.State().Value(e.ID())
+		if !found || types.IsUnknown(val) {
+			continue
+		}
+		text, err := parser.Unparse(e, native.SourceInfo())
+		if err != nil {
+			continue
+		}
+
+		var value any
+		if types.IsError(val) {
+			value = fmt.Sprintf("error: %v", val)
+		} else {
+			value = nativeValue(val)
+		}
+		explanation.Subexpressions = append(explanation.Subexpressions, models.Subexpression{Expression: text, Value: value})
+	}
+
+	return explanation, nil
+}
+
+// explainWalker collects the values paths and the evaluated subexpressions of a rule
+type explainWalker struct {
+	root           int64
+	seen           map[string]bool
+	paths          []string
+	subexpressions []ast.Expr
+}
+
+// walk visits the expression in evaluation order, inner subexpressions of comprehensions are
+// only searched for values paths since their state only holds the last iteration
+func (w *explainWalker) walk(e ast.Expr, inComprehension bool) {
+	if path, ok := valuesPath(e); ok {
+		if !w.seen[path] {
+			w.seen[path] = true
+			w.paths = append(w.paths, path)
+		}
+		if e.AsSelect().IsTestOnly() {
+			w.addSubexpression(e, inComprehension)
+		}
+		return
+	}
+
+	switch e.Kind() {
+	case ast.CallKind:
+		call := e.AsCall()
+		if call.IsMemberFunction() {
+			w.walk(call.Target(), inComprehension)
+		}
+		for _, arg := range call.Args() {
+			w.walk(arg, inComprehension)
+		}
+		w.addSubexpression(e, inComprehension)
+	case ast.SelectKind:
+		w.walk(e.AsSelect().Operand(), inComprehension)
+		if e.AsSelect().IsTestOnly() {
+			w.addSubexpression(e, inComprehension)
+		}
+	case ast.ListKind:
+		for _, element := range e.AsList().Elements() {
+			w.walk(element, inComprehension)
+		}
+	case ast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			w.walk(entry.AsMapEntry().Key(), inComprehension)
+			w.walk(entry.AsMapEntry().Value(), inComprehension)
+		}
+	case ast.StructKind:
+		for _, field := range e.AsStruct().Fields() {
+			w.walk(field.AsStructField().Value(), inComprehension)
+		}
+	case ast.ComprehensionKind:
+		comprehension := e.AsComprehension()
+		w.walk(comprehension.IterRange(), inComprehension)
+		w.walk(comprehension.AccuInit(), true)
+		w.walk(comprehension.LoopCondition(), true)
+		w.walk(comprehension.LoopStep(), true)
+		w.walk(comprehension.Result(), true)
+		w.addSubexpression(e, inComprehension)
+	}
+}
+
+func (w *explainWalker) addSubexpression(e ast.Expr, inComprehension bool) {
+	if inComprehension || e.ID() == w.root {
+		return
+	}
+	w.subexpressions = append(w.subexpressions, e)
+}
+
+// valuesPath returns the path of a field selection chain rooted at the values variable, e.g. values.service.port
+func valuesPath(e ast.Expr) (string, bool) {
+	fields := make([]string, 0)
+	for e.Kind() == ast.SelectKind {
+		fields = append([]string{e.AsSelect().FieldName()}, fields...)
+		e = e.AsSelect().Operand()
+	}
+	if len(fields) == 0 || e.Kind() != ast.IdentKind || e.AsIdent() != "values" {
+		return "", false
+	}
+	return "values." + strings.Join(fields, "."), true
+}
+
+// LookupValue returns the value at a values path, e.g. values.service.port
+func LookupValue(values map[string]any, path string) (any, bool) {
+	var current any = values
+	for _, field := range strings.Split(path, ".")[1:] {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[field]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// ValuesPaths returns the values paths referenced by an expression, e.g. values.service.port, in order of appearance
+func ValuesPaths(expr string) ([]string, error) {
+	env, err := newCelEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+	}
+
+	compiled, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %v", issues.Err())
+	}
+
+	native := compiled.NativeRep()
+	w := &explainWalker{root: native.Expr().ID(), seen: make(map[string]bool)}
+	w.walk(native.Expr(), false)
+
+	return w.paths, nil
+}
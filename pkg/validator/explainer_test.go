@@ -0,0 +1,120 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Explain(t *testing.T) {
+	values := map[string]any{
+		"replicas": 1,
+		"service": map[string]any{
+			"type": "NodePort",
+			"port": 8080,
+		},
+	}
+	rules := &models.ValidationRules{
+		Rules: []models.Rule{
+			{ID: "port", Expr: "${validPort(values.service.port)}", Desc: "port must be valid"},
+			{
+				ID:       "node-port",
+				Expr:     `values.service.type == "NodePort" ? has(values.service.nodePort) : true`,
+				Desc:     "nodePort must be set",
+				Severity: WarningSeverity,
+			},
+		},
+		Expressions: map[string]string{
+			"inRange":   "$0 >= $1 && $0 <= $2",
+			"validPort": "${inRange($0, 1, 65535)}",
+		},
+	}
+
+	v := New()
+	require.NoError(t, v.exprProcessor.PrepareNamedExpressions(rules))
+
+	t.Run(
+		"expansion steps", func(t *testing.T) {
+			rule, ok := FindRule(rules, "port")
+			require.True(t, ok)
+
+			explanation, err := v.Explain(rule, values, rules)
+			require.NoError(t, err)
+
+			assert.Equal(t, "${validPort(values.service.port)}", explanation.Expression)
+			assert.Equal(t, "((values.service.port >= 1 && values.service.port <= 65535))", explanation.Expanded)
+			assert.Equal(
+				t, []models.ExpansionStep{
+					{
+						Reference:   "${validPort(values.service.port)}",
+						Replacement: "${inRange(values.service.port, 1, 65535)}",
+						Result:      "(${inRange(values.service.port, 1, 65535)})",
+					},
+					{
+						Reference:   "${inRange(values.service.port, 1, 65535)}",
+						Replacement: "values.service.port >= 1 && values.service.port <= 65535",
+						Result:      "((values.service.port >= 1 && values.service.port <= 65535))",
+					},
+				}, explanation.Steps,
+			)
+			assert.Equal(
+				t, []models.ValueReference{{Path: "values.service.port", Value: 8080, Found: true}}, explanation.Values,
+			)
+			assert.True(t, explanation.Passed)
+		},
+	)
+
+	t.Run(
+		"subexpressions", func(t *testing.T) {
+			rule, ok := FindRule(rules, "1")
+			require.True(t, ok)
+
+			explanation, err := v.Explain(rule, values, rules)
+			require.NoError(t, err)
+
+			assert.Equal(t, WarningSeverity, explanation.Severity)
+			assert.Empty(t, explanation.Steps)
+			assert.Equal(
+				t, []models.ValueReference{
+					{Path: "values.service.type", Value: "NodePort", Found: true},
+					{Path: "values.service.nodePort"},
+				}, explanation.Values,
+			)
+			assert.Equal(
+				t, []models.Subexpression{
+					{Expression: `values.service.type == "NodePort"`, Value: true},
+					{Expression: "has(values.service.nodePort)", Value: false},
+				}, explanation.Subexpressions,
+			)
+			assert.False(t, explanation.Passed)
+			assert.Equal(t, false, explanation.Result)
+		},
+	)
+
+	t.Run(
+		"match rule", func(t *testing.T) {
+			rule := models.Rule{
+				Expr:  "object.spec.replicas >= 2",
+				Desc:  "deployments must be highly available",
+				Match: &models.Match{Kinds: []string{"Deployment"}},
+			}
+
+			_, err := v.Explain(rule, values, rules)
+			assert.EqualError(
+				t, err,
+				"rule 'deployments must be highly available' applies to rendered manifests and cannot be explained against values",
+			)
+		},
+	)
+
+	t.Run(
+		"unknown rule", func(t *testing.T) {
+			_, ok := FindRule(rules, "missing")
+			assert.False(t, ok)
+			_, ok = FindRule(rules, "2")
+			assert.False(t, ok)
+		},
+	)
+}
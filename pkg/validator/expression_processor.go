@@ -21,6 +21,9 @@ func (p *ExpressionProcessor) PrepareNamedExpressions(rules *models.ValidationRu
 	}
 
 	for i, rule := range rules.Rules {
+		if rule.Source == "" {
+			rules.Rules[i].Source = rule.Expr
+		}
 		expandedExpr, err := p.expandExpression(rule.Expr, rules.Expressions)
 		if err != nil {
 			return fmt.Errorf("failed to expand rule '%s': %v", rule.Desc, err)
@@ -33,6 +36,15 @@ func (p *ExpressionProcessor) PrepareNamedExpressions(rules *models.ValidationRu
 
 // expandExpression expands a single expression by replacing named expression references
 func (p *ExpressionProcessor) expandExpression(expr string, expressions map[string]string) (string, error) {
+	return p.expandExpressionSteps(expr, expressions, nil)
+}
+
+// expandExpressionSteps expands a single expression, recording every substitution into steps if it is not nil
+func (p *ExpressionProcessor) expandExpressionSteps(
+	expr string,
+	expressions map[string]string,
+	steps *[]models.ExpansionStep,
+) (string, error) {
 	if expressions == nil {
 		expressions = make(map[string]string)
 	}
@@ -81,6 +93,15 @@ func (p *ExpressionProcessor) expandExpression(expr string, expressions map[stri
 
 			// Replace the match with the expanded expression wrapped in parentheses
 			result = strings.Replace(result, fullMatch, "("+expandedExpr+")", 1)
+			if steps != nil {
+				*steps = append(
+					*steps, models.ExpansionStep{
+						Reference:   fullMatch,
+						Replacement: expandedExpr,
+						Result:      result,
+					},
+				)
+			}
 		}
 
 		if !foundReplacement {
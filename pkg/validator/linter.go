@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/parser"
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/idsulik/helm-cel/pkg/utils"
+)
+
+const (
+	// Checks reported by the linter
+	CheckSyntax             = "syntax"
+	CheckHasMisuse          = "has-misuse"
+	CheckUndefinedReference = "undefined-reference"
+	CheckUnusedExpression   = "unused-expression"
+	CheckDuplicateRule      = "duplicate-rule"
+	CheckConstantRule       = "constant-rule"
+	CheckMissingDescription = "missing-description"
+	CheckInvalidSeverity    = "invalid-severity"
+	CheckObjectWithoutMatch = "object-without-match"
+)
+
+// Linter finds problems in rules files without evaluating any values
+type Linter struct {
+	rulesLoader   *RulesLoader
+	exprProcessor *ExpressionProcessor
+}
+
+func NewLinter() *Linter {
+	return &Linter{
+		rulesLoader:   NewRulesLoader(),
+		exprProcessor: NewExpressionProcessor(),
+	}
+}
+
+// LintChart loads the rules files, relative to the chart path, and reports problems in them
+func (l *Linter) LintChart(chartPath string, rulesFiles []string) ([]*models.LintIssue, error) {
+	rulesFiles, err := utils.GetAbsolutePaths(chartPath, rulesFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules absolute paths: %v", err)
+	}
+
+	rules, err := l.rulesLoader.LoadAndMergeRules(rulesFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %v", err)
+	}
+
+	issues, err := l.Lint(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report files relative to the chart, as they were passed in
+	for _, issue := range issues {
+		if rel, err := filepath.Rel(chartPath, issue.File); err == nil && issue.File != "" {
+			issue.File = rel
+		}
+	}
+
+	return issues, nil
+}
+
+// Lint reports problems in merged, not yet expanded, rules
+func (l *Linter) Lint(rules *models.ValidationRules) ([]*models.LintIssue, error) {
+	env, err := newCelEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+	}
+
+	issues := make([]*models.LintIssue, 0)
+	seen := make(map[string]string)
+
+	for _, rule := range rules.Rules {
+		if rule.IsDisabled() {
+			continue
+		}
+
+		issue := func(check, severity, format string, args ...any) {
+			issues = append(
+				issues, &models.LintIssue{
+					File:     rule.File,
+					Rule:     ruleName(rule),
+					Check:    check,
+					Severity: severity,
+					Message:  fmt.Sprintf(format, args...),
+				},
+			)
+		}
+
+		if strings.TrimSpace(rule.Desc) == "" {
+			issue(CheckMissingDescription, WarningSeverity, "rule has no description")
+		}
+
+		switch rule.Severity {
+		case "", ErrorSeverity, WarningSeverity, InfoSeverity:
+		default:
+			issue(CheckInvalidSeverity, ErrorSeverity, "unknown severity '%s'", rule.Severity)
+		}
+
+		expr, err := l.exprProcessor.expandExpression(rule.Expr, rules.Expressions)
+		if err != nil {
+			issue(CheckUndefinedReference, ErrorSeverity, "%v", err)
+			continue
+		}
+
+		compiled, compileIssues := env.Compile(expr)
+		if compileIssues != nil && compileIssues.Err() != nil {
+			if misuses := hasMisuses(expr); len(misuses) > 0 {
+				issue(CheckHasMisuse, ErrorSeverity, "has() requires a field selection like has(values.a.b), got %s", strings.Join(misuses, ", "))
+			} else {
+				issue(CheckSyntax, ErrorSeverity, "%v", compileIssues.Err())
+			}
+			continue
+		}
+
+		normalized, err := cel.AstToString(compiled)
+		if err != nil {
+			normalized = expr
+		}
+		// The same expression on different manifests is not a duplicate
+		if rule.Match != nil {
+			normalized = fmt.Sprintf("%v %v %s", rule.Match.APIVersions, rule.Match.Kinds, normalized)
+		}
+		if previous, ok := seen[normalized]; ok {
+			issue(CheckDuplicateRule, WarningSeverity, "rule has the same expression as rule '%s'", previous)
+		} else {
+			seen[normalized] = ruleName(rule)
+		}
+
+		referencesObject := referencesVariable(compiled, "object")
+		if referencesObject && rule.Match == nil {
+			issue(CheckObjectWithoutMatch, ErrorSeverity, "rule references object but has no match, object is only set for rules matching rendered manifests")
+		}
+
+		if !referencesVariable(compiled, "values") && !referencesObject {
+			if value, ok := constantValue(env, compiled); ok {
+				issue(CheckConstantRule, WarningSeverity, "rule does not reference values and always evaluates to %v", value)
+			}
+		}
+	}
+
+	for _, name := range unusedExpressions(l.exprProcessor, rules) {
+		issues = append(
+			issues, &models.LintIssue{
+				Check:    CheckUnusedExpression,
+				Severity: WarningSeverity,
+				Message:  fmt.Sprintf("named expression '%s' is never used", name),
+			},
+		)
+	}
+
+	return issues, nil
+}
+
+// ruleName identifies a rule in lint issues
+func ruleName(rule models.Rule) string {
+	if rule.ID != "" {
+		return rule.ID
+	}
+	return rule.Desc
+}
+
+// hasMisuses returns the has() calls of an expression whose argument is not a field selection. The expression is
+// parsed without macros, so these calls are kept as written instead of failing the expansion of the has() macro.
+func hasMisuses(expr string) []string {
+	p, err := parser.NewParser()
+	if err != nil {
+		return nil
+	}
+	parsed, errs := p.Parse(common.NewTextSource(expr))
+	if len(errs.GetErrors()) > 0 {
+		return nil
+	}
+
+	misuses := make([]string, 0)
+	for _, call := range ast.MatchDescendants(ast.NavigateAST(parsed), ast.FunctionMatcher("has")) {
+		args := call.AsCall().Args()
+		if call.AsCall().IsMemberFunction() || (len(args) == 1 && args[0].Kind() == ast.SelectKind) {
+			continue
+		}
+		text, err := parser.Unparse(call, parsed.SourceInfo())
+		if err != nil {
+			text = "has()"
+		}
+		misuses = append(misuses, text)
+	}
+	return misuses
+}
+
+// referencesVariable reports whether the expression references the named variable
+func referencesVariable(compiled *cel.Ast, name string) bool {
+	idents := ast.MatchDescendants(
+		ast.NavigateAST(compiled.NativeRep()),
+		ast.KindMatcher(ast.IdentKind),
+	)
+	for _, ident := range idents {
+		if ident.AsIdent() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// constantValue evaluates an expression that does not depend on any variable
+func constantValue(env *cel.Env, compiled *cel.Ast) (any, bool) {
+	program, err := env.Program(compiled)
+	if err != nil {
+		return nil, false
+	}
+	out, _, err := program.Eval(map[string]any{"values": map[string]any{}})
+	if err != nil {
+		return nil, false
+	}
+	return out.Value(), true
+}
+
+// unusedExpressions returns the sorted names of named expressions not reachable from any rule
+func unusedExpressions(p *ExpressionProcessor, rules *models.ValidationRules) []string {
+	used := make(map[string]bool)
+	var visit func(expr string)
+	visit = func(expr string) {
+		for _, match := range p.findExpressionReferences(expr) {
+			if used[match.name] {
+				continue
+			}
+			used[match.name] = true
+			if namedExpr, ok := rules.Expressions[match.name]; ok {
+				visit(namedExpr)
+			}
+		}
+	}
+	for _, rule := range rules.Rules {
+		visit(rule.Expr)
+	}
+
+	unused := make([]string, 0)
+	for name := range rules.Expressions {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
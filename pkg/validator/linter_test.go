@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_LintChart(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		expected []string
+	}{
+		{
+			name: "valid rules",
+			rules: `
+expressions:
+  validPort: "values.service.port <= 65535"
+rules:
+  - id: port
+    expr: "${validPort}"
+    desc: "port must be valid"`,
+			expected: []string{},
+		},
+		{
+			name: "missing description and invalid severity",
+			rules: `
+rules:
+  - expr: "values.replicas > 0"
+    severity: critical`,
+			expected: []string{CheckMissingDescription, CheckInvalidSeverity},
+		},
+		{
+			name: "syntax error and has misuse",
+			rules: `
+rules:
+  - expr: "values.replicas >>"
+    desc: "broken rule"
+  - expr: "has(values)"
+    desc: "has on identifier"
+  - expr: 'values.replicas > 0 && has(values["image"])'
+    desc: "has on index"`,
+			expected: []string{CheckSyntax, CheckHasMisuse, CheckHasMisuse},
+		},
+		{
+			name: "undefined and unused expressions",
+			rules: `
+expressions:
+  unused: "values.debug"
+rules:
+  - expr: "${missing}"
+    desc: "undefined reference"`,
+			expected: []string{CheckUndefinedReference, CheckUnusedExpression},
+		},
+		{
+			name: "duplicate and constant rules",
+			rules: `
+expressions:
+  positive: "values.replicas > 0"
+rules:
+  - id: first
+    expr: "${positive}"
+    desc: "replicas must be positive"
+  - id: second
+    expr: "values.replicas>0"
+    desc: "replicas must be positive again"
+  - id: constant
+    expr: "1 < 2"
+    desc: "always true"`,
+			expected: []string{CheckDuplicateRule, CheckConstantRule},
+		},
+		{
+			name: "object without match",
+			rules: `
+rules:
+  - expr: "has(object.spec.replicas)"
+    desc: "replicas must be set"
+  - match:
+      kinds: [Deployment]
+    expr: "has(object.spec.replicas)"
+    desc: "deployments must set replicas"`,
+			expected: []string{CheckObjectWithoutMatch},
+		},
+		{
+			name: "disabled rules are ignored",
+			rules: `
+rules:
+  - expr: "values.replicas >>"
+    disabled: true`,
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tempDir := t.TempDir()
+				require.NoError(t, writeFile(t, tempDir, "values.cel.yaml", tt.rules))
+
+				issues, err := NewLinter().LintChart(tempDir, []string{"values.cel.yaml"})
+				require.NoError(t, err)
+
+				checks := make([]string, 0, len(issues))
+				for _, issue := range issues {
+					checks = append(checks, issue.Check)
+					if issue.Check != CheckUnusedExpression {
+						assert.Equal(t, "values.cel.yaml", issue.File)
+					}
+				}
+				assert.Equal(t, tt.expected, checks)
+			},
+		)
+	}
+}
+
+func TestLinter_LintChart_DuplicateMessage(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - id: first
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+  - id: second
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive again"`,
+		),
+	)
+
+	issues, err := NewLinter().LintChart(tempDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	assert.Equal(
+		t,
+		"values.cel.yaml: warning [duplicate-rule] rule 'second': rule has the same expression as rule 'first'",
+		issues[0].String(),
+	)
+}
+
+func TestLinter_LintChart_HasMisuseMessage(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - id: image
+    expr: 'has(values.image) && has(values["image"]["tag"])'
+    desc: "image tag must be set"`,
+		),
+	)
+
+	issues, err := NewLinter().LintChart(tempDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+
+	assert.Equal(
+		t,
+		`values.cel.yaml: error [has-misuse] rule 'image': has() requires a field selection like has(values.a.b), got has(values["image"]["tag"])`,
+		issues[0].String(),
+	)
+}
@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ManifestsLoader struct{}
+
+func NewManifestsLoader() *ManifestsLoader {
+	return &ManifestsLoader{}
+}
+
+// LoadManifests reads a multi-document YAML stream of rendered manifests, such as the output of helm template,
+// skipping empty documents
+func (l *ManifestsLoader) LoadManifests(r io.Reader) ([]map[string]any, error) {
+	manifests := make([]map[string]any, 0)
+	decoder := yaml.NewDecoder(r)
+
+	for i := 0; ; i++ {
+		var manifest map[string]any
+		err := decoder.Decode(&manifest)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %d: %v", i, err)
+		}
+		if len(manifest) == 0 {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests, nil
+}
+
+// resourceName identifies a manifest in validation results, e.g. Deployment/default/app
+func resourceName(manifest map[string]any) string {
+	kind, _ := manifest["kind"].(string)
+	metadata, _ := manifest["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	parts := []string{kind}
+	if namespace != "" {
+		parts = append(parts, namespace)
+	}
+	return strings.Join(append(parts, name), "/")
+}
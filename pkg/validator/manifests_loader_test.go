@@ -0,0 +1,181 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestsLoader_LoadManifests(t *testing.T) {
+	rendered := `---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+# Source: mychart/templates/empty.yaml
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: prod
+`
+
+	manifests, err := NewManifestsLoader().LoadManifests(strings.NewReader(rendered))
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "Service/app", resourceName(manifests[0]))
+	assert.Equal(t, "Deployment/prod/app", resourceName(manifests[1]))
+
+	_, err = NewManifestsLoader().LoadManifests(strings.NewReader("kind: [\n"))
+	assert.ErrorContains(t, err, "failed to parse manifest 0")
+}
+
+func TestValidator_ValidateManifests(t *testing.T) {
+	manifests, err := NewManifestsLoader().LoadManifests(
+		strings.NewReader(
+			`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: limited
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            limits:
+              cpu: "1"
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: unlimited
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  volumes:
+    - name: root
+      hostPath:
+        path: /
+`,
+		),
+	)
+	require.NoError(t, err)
+
+	tempDir := t.TempDir()
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - id: replicas
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+  - id: limits
+    match:
+      apiVersions: [apps/v1]
+      kinds: [Deployment]
+    expr: "object.spec.template.spec.containers.all(c, has(c.resources) && has(c.resources.limits))"
+    desc: "containers must have resource limits"
+  - id: replicas-match
+    match:
+      kinds: [Deployment]
+    expr: "object.spec.replicas >= values.replicas"
+    desc: "deployments must have at least the configured replicas"
+    severity: warning
+  - id: no-host-path
+    match: {}
+    expr: "!has(object.spec) || !has(object.spec.volumes) || object.spec.volumes.all(v, !has(v.hostPath))"
+    desc: "hostPath volumes are not allowed"
+  - id: ingress-tls
+    match:
+      kinds: [Ingress]
+    expr: "has(object.spec.tls)"
+    desc: "ingresses must use TLS"
+  - id: legacy
+    expr: "false"
+    desc: "legacy rule"
+    disabled: true
+`,
+		),
+	)
+
+	v := New()
+	rules, err := v.LoadChartRules(tempDir, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	result, err := v.ValidateManifests(map[string]any{"replicas": 2}, manifests, rules)
+	require.NoError(t, err)
+
+	failures := func(errs []*models.ValidationError) []string {
+		got := make([]string, 0, len(errs))
+		for _, e := range errs {
+			got = append(got, e.RuleID+" "+e.Resource)
+		}
+		return got
+	}
+	assert.Equal(t, []string{"limits Deployment/unlimited", "no-host-path Pod/debug"}, failures(result.Errors))
+	assert.Equal(t, []string{"replicas-match Deployment/unlimited"}, failures(result.Warnings))
+
+	passes := make([]string, 0, len(result.Passed))
+	for _, p := range result.Passed {
+		passes = append(passes, p.RuleID+" "+p.Resource)
+	}
+	assert.Equal(
+		t, []string{
+			"replicas ",
+			"limits Deployment/limited",
+			"replicas-match Deployment/limited",
+			"no-host-path Deployment/limited",
+			"no-host-path Deployment/unlimited",
+		}, passes,
+	)
+
+	skips := func(result *models.ValidationResult) []string {
+		got := make([]string, 0, len(result.Skipped))
+		for _, s := range result.Skipped {
+			got = append(got, s.RuleID+": "+s.Reason)
+		}
+		return got
+	}
+	assert.Equal(t, []string{"ingress-tls: no matching manifests", "legacy: disabled"}, skips(result))
+
+	summary := result.Summary()
+	assert.Equal(t, 6, summary.Rules)
+	assert.Equal(t, 1, summary.Passed)
+	assert.Equal(t, 3, summary.Failed)
+	assert.Equal(t, 2, summary.Skipped)
+	assert.Equal(t, 2, summary.Errors)
+	assert.Equal(t, 1, summary.Warnings)
+
+	// Without manifests, only the rules on values are evaluated
+	result, err = v.Validate(map[string]any{"replicas": 0}, rules)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"replicas "}, failures(result.Errors))
+	assert.Empty(t, result.Warnings)
+	assert.Equal(
+		t, []string{
+			"limits: no rendered manifests",
+			"replicas-match: no rendered manifests",
+			"no-host-path: no rendered manifests",
+			"ingress-tls: no rendered manifests",
+			"legacy: disabled",
+		}, skips(result),
+	)
+}
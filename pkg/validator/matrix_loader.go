@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+type MatrixLoader struct{}
+
+func NewMatrixLoader() *MatrixLoader {
+	return &MatrixLoader{}
+}
+
+// LoadMatrix reads a matrix file mapping environment names to stacks of values files, e.g.
+// prod: [values.yaml, prod.yaml], and returns the environments in the order they are defined
+func (l *MatrixLoader) LoadMatrix(path string) ([]models.Environment, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read matrix file: %v", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse matrix file: %v", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("matrix file %s must map environment names to lists of values files", path)
+	}
+
+	mapping := document.Content[0]
+	environments := make([]models.Environment, 0, len(mapping.Content)/2)
+	names := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		name := mapping.Content[i].Value
+		if names[name] {
+			return nil, fmt.Errorf("duplicate environment '%s' in matrix file %s", name, path)
+		}
+		names[name] = true
+
+		var valuesFiles []string
+		if err := mapping.Content[i+1].Decode(&valuesFiles); err != nil {
+			return nil, fmt.Errorf("invalid values files of environment '%s' in matrix file %s: %v", name, path, err)
+		}
+		if len(valuesFiles) == 0 {
+			return nil, fmt.Errorf("environment '%s' in matrix file %s has no values files", name, path)
+		}
+		environments = append(environments, models.Environment{Name: name, ValuesFiles: valuesFiles})
+	}
+
+	if len(environments) == 0 {
+		return nil, fmt.Errorf("matrix file %s has no environments", path)
+	}
+	return environments, nil
+}
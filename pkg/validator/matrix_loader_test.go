@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixLoader_LoadMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []models.Environment
+		wantErr string
+	}{
+		{
+			name: "environments in file order",
+			content: `
+prod: [values.yaml, prod.yaml, prod-eu.yaml]
+dev:
+  - values.yaml
+  - dev.yaml`,
+			want: []models.Environment{
+				{Name: "prod", ValuesFiles: []string{"values.yaml", "prod.yaml", "prod-eu.yaml"}},
+				{Name: "dev", ValuesFiles: []string{"values.yaml", "dev.yaml"}},
+			},
+		},
+		{
+			name:    "not a mapping",
+			content: "- values.yaml",
+			wantErr: "must map environment names to lists of values files",
+		},
+		{
+			name:    "no environments",
+			content: "{}",
+			wantErr: "has no environments",
+		},
+		{
+			name:    "values files not a list",
+			content: "dev: values.yaml",
+			wantErr: "invalid values files of environment 'dev'",
+		},
+		{
+			name:    "no values files",
+			content: "dev: []",
+			wantErr: "environment 'dev' in matrix file",
+		},
+		{
+			name:    "duplicate environment",
+			content: "dev: [values.yaml]\ndev: [dev.yaml]",
+			wantErr: "duplicate environment 'dev'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "envs.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+				environments, err := NewMatrixLoader().LoadMatrix(path)
+				if tt.wantErr != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, environments)
+			},
+		)
+	}
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+)
+
+// applyProfile applies the severity overrides and disabled rules of the named profile
+func applyProfile(rules *models.ValidationRules, name string) error {
+	profile, ok := rules.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile '%s' not found (available profiles: %v)", name, profileNames(rules))
+	}
+
+	for id, override := range profile.Rules {
+		found := false
+		for i := range rules.Rules {
+			if rules.Rules[i].ID != id {
+				continue
+			}
+			found = true
+			if override.Severity != "" {
+				rules.Rules[i].Severity = override.Severity
+			}
+			if override.Disabled != nil {
+				rules.Rules[i].Disabled = override.Disabled
+			}
+		}
+		if !found {
+			return fmt.Errorf("profile '%s' overrides unknown rule '%s'", name, id)
+		}
+	}
+
+	return nil
+}
+
+// profileNames returns the sorted names of all profiles defined in the rules
+func profileNames(rules *models.ValidationRules) []string {
+	names := make([]string, 0, len(rules.Profiles))
+	for name := range rules.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -19,34 +19,134 @@ func (l *RulesLoader) LoadAndMergeRules(rulesFiles []string) (*models.Validation
 	mergedRules := &models.ValidationRules{
 		Rules:       make([]models.Rule, 0),
 		Expressions: make(map[string]string),
+		Profiles:    make(map[string]models.Profile),
 	}
 
+	ruleIndex := make(map[string]int)
+
 	for _, path := range rulesFiles {
 		rules, err := l.loadRulesFile(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load rules from %s: %v", path, err)
 		}
 
-		// Merge rules
-		mergedRules.Rules = append(mergedRules.Rules, rules.Rules...)
+		// Merge rules, letting rules with a known ID override the earlier definition
+		fileIDs := make(map[string]bool)
+		for _, rule := range rules.Rules {
+			rule.File = path
+			if rule.ID == "" {
+				mergedRules.Rules = append(mergedRules.Rules, rule)
+				continue
+			}
 
-		// Merge expressions, checking for duplicates
+			if fileIDs[rule.ID] {
+				return nil, fmt.Errorf("duplicate rule id '%s' found in %s", rule.ID, path)
+			}
+			fileIDs[rule.ID] = true
+
+			if i, ok := ruleIndex[rule.ID]; ok {
+				mergedRules.Rules[i] = overrideRule(mergedRules.Rules[i], rule)
+				continue
+			}
+			if rule.Expr == "" {
+				return nil, fmt.Errorf(
+					"rule '%s' in %s has no expr and does not override a rule defined in an earlier rules file",
+					rule.ID,
+					path,
+				)
+			}
+
+			ruleIndex[rule.ID] = len(mergedRules.Rules)
+			mergedRules.Rules = append(mergedRules.Rules, rule)
+		}
+
+		// Merge expressions, checking for duplicates unless an override is requested
 		for k, v := range rules.Expressions {
-			if existing, ok := mergedRules.Expressions[k]; ok {
+			existing, ok := mergedRules.Expressions[k]
+			override := rules.ExpressionOverrides[k]
+			if ok && !override {
 				return nil, fmt.Errorf(
-					"duplicate named expression '%s' found in %s (already defined as '%s')",
+					"duplicate named expression '%s' found in %s (already defined as '%s', set override: true to replace it)",
 					k,
 					path,
 					existing,
 				)
 			}
+			if !ok && override {
+				return nil, fmt.Errorf(
+					"named expression '%s' in %s is marked as override but is not defined in an earlier rules file",
+					k,
+					path,
+				)
+			}
 			mergedRules.Expressions[k] = v
 		}
+
+		// Merge profiles, checking for duplicate rule overrides
+		for name, profile := range rules.Profiles {
+			merged, ok := mergedRules.Profiles[name]
+			if !ok {
+				merged = models.Profile{Rules: make(map[string]models.RuleOverride)}
+			}
+			for id, override := range profile.Rules {
+				if _, ok := merged.Rules[id]; ok {
+					return nil, fmt.Errorf(
+						"duplicate override for rule '%s' in profile '%s' found in %s",
+						id,
+						name,
+						path,
+					)
+				}
+				merged.Rules[id] = override
+			}
+			mergedRules.Profiles[name] = merged
+		}
+
+		// Merge admission resources, checking for duplicate names
+		if rules.Admission != nil {
+			if mergedRules.Admission == nil {
+				mergedRules.Admission = &models.Admission{}
+			}
+			for _, resource := range rules.Admission.Resources {
+				for _, existing := range mergedRules.Admission.Resources {
+					if existing.Name == resource.Name {
+						return nil, fmt.Errorf("duplicate admission resource '%s' found in %s", resource.Name, path)
+					}
+				}
+				mergedRules.Admission.Resources = append(mergedRules.Admission.Resources, resource)
+			}
+		}
 	}
 
 	return mergedRules, nil
 }
 
+// overrideRule applies the fields set in a later rule to an earlier rule with the same ID, the rule is then
+// located in the file of the later rule
+func overrideRule(base, override models.Rule) models.Rule {
+	base.File = override.File
+	if override.Expr != "" {
+		base.Expr = override.Expr
+		base.Source = override.Source
+	}
+	if override.Desc != "" {
+		base.Desc = override.Desc
+	}
+	if override.Severity != "" {
+		base.Severity = override.Severity
+	}
+	if override.Disabled != nil {
+		base.Disabled = override.Disabled
+	}
+	if len(override.Tags) > 0 {
+		base.Tags = override.Tags
+	}
+	if override.Match != nil {
+		base.Match = override.Match
+	}
+	return base
+}
+
 // loadRulesFile loads validation rules from a specific file
 func (l *RulesLoader) loadRulesFile(path string) (*models.ValidationRules, error) {
 	content, err := os.ReadFile(path)
@@ -0,0 +1,265 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/idsulik/helm-cel/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesLoader_LoadAndMergeRules(t *testing.T) {
+	disabled, enabled := true, false
+	tests := []struct {
+		name    string
+		files   []string
+		want    *models.ValidationRules
+		wantErr string
+	}{
+		{
+			name: "rules without ids are appended",
+			files: []string{
+				`
+rules:
+  - expr: "values.replicas > 0"
+    desc: "replicas must be positive"`,
+				`
+rules:
+  - expr: "values.replicas > 0"
+    desc: "replicas must be positive"`,
+			},
+			want: &models.ValidationRules{
+				Rules: []models.Rule{
+					{Expr: "values.replicas > 0", Desc: "replicas must be positive", File: "rules0.cel.yaml"},
+					{Expr: "values.replicas > 0", Desc: "replicas must be positive", File: "rules1.cel.yaml"},
+				},
+				Expressions: map[string]string{},
+				Profiles:    map[string]models.Profile{},
+			},
+		},
+		{
+			name: "later file overrides rule with same id",
+			files: []string{
+				`
+rules:
+  - id: replicas
+    expr: "values.replicas > 1"
+    desc: "replicas must be highly available"
+  - id: tag
+    expr: 'values.image.tag != "latest"'
+    desc: "image tag must be pinned"`,
+				`
+rules:
+  - id: replicas
+    severity: warning
+  - id: tag
+    disabled: true
+  - id: debug
+    expr: "!values.debug"
+    desc: "debug must be disabled"`,
+			},
+			want: &models.ValidationRules{
+				Rules: []models.Rule{
+					{ID: "replicas", Expr: "values.replicas > 1", Desc: "replicas must be highly available", Severity: "warning", File: "rules1.cel.yaml"},
+					{ID: "tag", Expr: `values.image.tag != "latest"`, Desc: "image tag must be pinned", Disabled: &disabled, File: "rules1.cel.yaml"},
+					{ID: "debug", Expr: "!values.debug", Desc: "debug must be disabled", File: "rules1.cel.yaml"},
+				},
+				Expressions: map[string]string{},
+				Profiles:    map[string]models.Profile{},
+			},
+		},
+		{
+			name: "later file enables a disabled rule",
+			files: []string{
+				`
+rules:
+  - id: tag
+    expr: 'values.image.tag != "latest"'
+    desc: "image tag must be pinned"
+    disabled: true`,
+				`
+rules:
+  - id: tag
+    severity: warning`,
+				`
+rules:
+  - id: tag
+    disabled: false`,
+			},
+			want: &models.ValidationRules{
+				Rules: []models.Rule{
+					{ID: "tag", Expr: `values.image.tag != "latest"`, Desc: "image tag must be pinned", Severity: "warning", Disabled: &enabled, File: "rules2.cel.yaml"},
+				},
+				Expressions: map[string]string{},
+				Profiles:    map[string]models.Profile{},
+			},
+		},
+		{
+			name: "override of an unknown rule",
+			files: []string{
+				`
+rules:
+  - id: replicas
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive"`,
+				`
+rules:
+  - id: tag
+    severity: warning`,
+			},
+			wantErr: "rules1.cel.yaml has no expr and does not override a rule defined in an earlier rules file",
+		},
+		{
+			name: "duplicate id within a file",
+			files: []string{
+				`
+rules:
+  - id: replicas
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+  - id: replicas
+    expr: "values.replicas > 1"
+    desc: "replicas must be highly available"`,
+			},
+			wantErr: "duplicate rule id 'replicas'",
+		},
+		{
+			name: "expression override",
+			files: []string{
+				`
+expressions:
+  minReplicas: "values.replicas >= 2"
+rules:
+  - expr: "${minReplicas}"
+    desc: "replicas must be highly available"`,
+				`
+expressions:
+  minReplicas:
+    expr: "values.replicas >= 1"
+    override: true`,
+			},
+			want: &models.ValidationRules{
+				Rules: []models.Rule{
+					{Expr: "${minReplicas}", Desc: "replicas must be highly available", File: "rules0.cel.yaml"},
+				},
+				Expressions: map[string]string{"minReplicas": "values.replicas >= 1"},
+				Profiles:    map[string]models.Profile{},
+			},
+		},
+		{
+			name: "duplicate expression without override",
+			files: []string{
+				`
+expressions:
+  minReplicas: "values.replicas >= 2"`,
+				`
+expressions:
+  minReplicas: "values.replicas >= 1"`,
+			},
+			wantErr: "duplicate named expression 'minReplicas'",
+		},
+		{
+			name: "override of undefined expression",
+			files: []string{
+				`
+expressions:
+  minReplicas:
+    expr: "values.replicas >= 1"
+    override: true`,
+			},
+			wantErr: "named expression 'minReplicas'",
+		},
+		{
+			name: "admission resources are merged",
+			files: []string{
+				`
+admission:
+  resources:
+    - name: deployment
+      apiGroups: ["apps"]
+      apiVersions: ["v1"]
+      resources: ["deployments"]
+      values:
+        replicaCount: object.spec.replicas`,
+				`
+admission:
+  resources:
+    - name: service
+      apiGroups: [""]
+      apiVersions: ["v1"]
+      resources: ["services"]
+      values:
+        service.port: object.spec.ports[0].port`,
+			},
+			want: &models.ValidationRules{
+				Rules:       []models.Rule{},
+				Expressions: map[string]string{},
+				Profiles:    map[string]models.Profile{},
+				Admission: &models.Admission{
+					Resources: []models.AdmissionResource{
+						{
+							Name:        "deployment",
+							APIGroups:   []string{"apps"},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"deployments"},
+							Values:      map[string]string{"replicaCount": "object.spec.replicas"},
+						},
+						{
+							Name:        "service",
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"services"},
+							Values:      map[string]string{"service.port": "object.spec.ports[0].port"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate admission resource",
+			files: []string{
+				`
+admission:
+  resources:
+    - name: deployment`,
+				`
+admission:
+  resources:
+    - name: deployment`,
+			},
+			wantErr: "duplicate admission resource 'deployment'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tempDir := t.TempDir()
+				paths := make([]string, 0, len(tt.files))
+				for i, content := range tt.files {
+					name := fmt.Sprintf("rules%d.cel.yaml", i)
+					require.NoError(t, writeFile(t, tempDir, name, content))
+					paths = append(paths, filepath.Join(tempDir, name))
+				}
+
+				got, err := NewRulesLoader().LoadAndMergeRules(paths)
+				if tt.wantErr != "" {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tt.wantErr)
+					return
+				}
+
+				require.NoError(t, err)
+				for i := range got.Rules {
+					got.Rules[i].File = filepath.Base(got.Rules[i].File)
+				}
+				assert.Equal(t, tt.want.Rules, got.Rules)
+				assert.Equal(t, tt.want.Expressions, got.Expressions)
+				assert.Equal(t, tt.want.Profiles, got.Profiles)
+				assert.Equal(t, tt.want.Admission, got.Admission)
+			},
+		)
+	}
+}
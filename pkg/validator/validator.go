@@ -5,16 +5,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
 	"github.com/idsulik/helm-cel/pkg/models"
 	"github.com/idsulik/helm-cel/pkg/utils"
 	"gopkg.in/yaml.v3"
 )
 
 const (
+	// ErrorSeverity represents a validation error, the default severity
+	ErrorSeverity = "error"
 	// WarningSeverity represents a validation warning
 	WarningSeverity = "warning"
+	// InfoSeverity represents an informational finding that never fails validation
+	InfoSeverity = "info"
 )
 
 // Validator handles the validation of Helm values using CEL
@@ -23,14 +29,60 @@ type Validator struct {
 	valuesLoader  *ValuesLoader
 	rulesLoader   *RulesLoader
 	exprProcessor *ExpressionProcessor
+	profile       string
+	observers     []EvalObserver
+	programs      map[string]*compiledRule // by expression, reused when validating several sets of values
 }
 
-func New() *Validator {
-	return &Validator{
+// compiledRule is the program of a rule expression, or why it could not be compiled
+type compiledRule struct {
+	ast       *cel.Ast
+	program   cel.Program
+	syntaxErr error
+	err       error
+}
+
+// Option configures a Validator
+type Option func(*Validator)
+
+// Evaluation describes the evaluation of a single rule
+type Evaluation struct {
+	Index   int // position of the rule in the merged rules
+	Rule    models.Rule
+	AST     *cel.Ast
+	Result  ref.Val // error value if the evaluation failed
+	Details *cel.EvalDetails
+	Err     error
+}
+
+// EvalObserver is called after each rule evaluation, e.g. to collect coverage
+type EvalObserver func(*Evaluation)
+
+// WithProfile selects the rules profile applied after the rules files are merged
+func WithProfile(profile string) Option {
+	return func(v *Validator) {
+		v.profile = profile
+	}
+}
+
+// WithEvalObserver registers an observer that receives every rule evaluation,
+// evaluation state is tracked for each sub-expression when observers are present
+func WithEvalObserver(observer EvalObserver) Option {
+	return func(v *Validator) {
+		v.observers = append(v.observers, observer)
+	}
+}
+
+func New(opts ...Option) *Validator {
+	v := &Validator{
 		valuesLoader:  NewValuesLoader(),
 		rulesLoader:   NewRulesLoader(),
 		exprProcessor: NewExpressionProcessor(),
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // ValidateChart validates the values.yaml file against CEL rules.
@@ -39,16 +91,24 @@ func (v *Validator) ValidateChart(
 	valuesFiles []string,
 	rulesFiles []string,
 ) (*models.ValidationResult, error) {
-	// Process values files
-	valuesFiles, err := utils.GetAbsolutePaths(chartPath, valuesFiles)
+	mergedValues, err := v.LoadChartValues(chartPath, valuesFiles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get values absolute paths: %v", err)
+		return nil, err
 	}
 
-	// Process rules files
-	rulesFiles, err = utils.GetAbsolutePaths(chartPath, rulesFiles)
+	mergedRules, err := v.LoadChartRules(chartPath, rulesFiles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules absolute paths: %v", err)
+		return nil, err
+	}
+
+	return v.Validate(mergedValues, mergedRules)
+}
+
+// LoadChartValues loads and merges the values files, relative to the chart path
+func (v *Validator) LoadChartValues(chartPath string, valuesFiles []string) (map[string]any, error) {
+	valuesFiles, err := utils.GetAbsolutePaths(chartPath, valuesFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values absolute paths: %v", err)
 	}
 
 	mergedValues, err := v.valuesLoader.LoadAndMergeValues(valuesFiles)
@@ -56,35 +116,117 @@ func (v *Validator) ValidateChart(
 		return nil, fmt.Errorf("failed to load values: %v", err)
 	}
 
+	return mergedValues, nil
+}
+
+// LoadChartRules loads and merges the rules files, relative to the chart path,
+// applies the selected profile and expands named expressions
+func (v *Validator) LoadChartRules(chartPath string, rulesFiles []string) (*models.ValidationRules, error) {
+	rulesFiles, err := utils.GetAbsolutePaths(chartPath, rulesFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules absolute paths: %v", err)
+	}
+
 	mergedRules, err := v.rulesLoader.LoadAndMergeRules(rulesFiles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load rules: %v", err)
 	}
 
+	if v.profile != "" {
+		if err := applyProfile(mergedRules, v.profile); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(mergedRules.Rules) == 0 {
-		return &models.ValidationResult{}, nil
+		return mergedRules, nil
 	}
 
-	env, err := v.initCelEnv()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+	if err := validateSeverities(mergedRules); err != nil {
+		return nil, err
 	}
-	v.env = env
 
 	if err := v.exprProcessor.PrepareNamedExpressions(mergedRules); err != nil {
 		return nil, err
 	}
 
-	return v.validateRules(mergedValues, mergedRules), nil
+	return mergedRules, nil
+}
+
+// Validate validates values against rules prepared by LoadChartRules
+func (v *Validator) Validate(values map[string]any, rules *models.ValidationRules) (*models.ValidationResult, error) {
+	if len(rules.Rules) == 0 {
+		return &models.ValidationResult{}, nil
+	}
+
+	if v.env == nil {
+		env, err := v.initCelEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+		}
+		v.env = env
+	}
+
+	return v.validateRules(values, nil, rules), nil
+}
+
+// ValidateManifests validates values and rendered manifests against rules prepared by LoadChartRules,
+// rules with a match are evaluated against each matching manifest
+func (v *Validator) ValidateManifests(
+	values map[string]any,
+	manifests []map[string]any,
+	rules *models.ValidationRules,
+) (*models.ValidationResult, error) {
+	if len(rules.Rules) == 0 {
+		return &models.ValidationResult{}, nil
+	}
+
+	if v.env == nil {
+		env, err := v.initCelEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CEL environment: %v", err)
+		}
+		v.env = env
+	}
+
+	if manifests == nil {
+		manifests = make([]map[string]any, 0)
+	}
+	return v.validateRules(values, manifests, rules), nil
 }
 
 // initCelEnv initializes the CEL environment with required variables and functions
 func (v *Validator) initCelEnv() (*cel.Env, error) {
+	return newCelEnv()
+}
+
+// newCelEnv creates the CEL environment shared by validation and rule analysis
+func newCelEnv() (*cel.Env, error) {
 	return cel.NewEnv(
 		cel.Variable("values", cel.DynType),
+		cel.Variable("object", cel.DynType),
 	)
 }
 
+// validateSeverities ensures every rule uses a known severity level
+func validateSeverities(rules *models.ValidationRules) error {
+	for _, rule := range rules.Rules {
+		switch rule.Severity {
+		case "", ErrorSeverity, WarningSeverity, InfoSeverity:
+		default:
+			return fmt.Errorf(
+				"invalid severity '%s' in rule '%s' (must be one of %s, %s, %s)",
+				rule.Severity,
+				rule.Desc,
+				ErrorSeverity,
+				WarningSeverity,
+				InfoSeverity,
+			)
+		}
+	}
+	return nil
+}
+
 // loadValues reads and parses the values.yaml file from the chart path
 func (v *Validator) loadValues(chartPath string) (map[string]any, error) {
 	valuesPath := filepath.Join(chartPath, "values.yaml")
@@ -117,75 +259,211 @@ func (v *Validator) loadRules(chartPath string) (*models.ValidationRules, error)
 	return &rules, nil
 }
 
-// validateRules validates values against all rules and returns the validation result
+// validateRules validates values against all rules and returns the validation result. Rules with a match are only
+// evaluated when manifests are given, once for each matching manifest exposed as object. Rules that are not
+// evaluated are recorded as skipped.
 func (v *Validator) validateRules(
 	values map[string]any,
+	manifests []map[string]any,
 	rules *models.ValidationRules,
 ) *models.ValidationResult {
 	result := &models.ValidationResult{
 		Errors:   make([]*models.ValidationError, 0),
 		Warnings: make([]*models.ValidationError, 0),
+		Infos:    make([]*models.ValidationError, 0),
 	}
 
-	for _, rule := range rules.Rules {
-		ast, issues := v.env.Compile(rule.Expr)
-		if issues != nil && issues.Err() != nil {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	var programOpts []cel.ProgramOption
+	if len(v.observers) > 0 {
+		programOpts = append(programOpts, cel.EvalOptions(cel.OptTrackState))
+	}
+
+	for i, rule := range rules.Rules {
+		switch {
+		case rule.IsDisabled():
+			addSkip(result, i, rule, "disabled")
+			continue
+		case rule.Match != nil && manifests == nil:
+			addSkip(result, i, rule, "no rendered manifests")
+			continue
+		}
+
+		compiled := v.compile(rule.Expr, programOpts)
+		if compiled.syntaxErr != nil {
 			result.Errors = append(
 				result.Errors, &models.ValidationError{
-					Description: fmt.Sprintf("Invalid rule syntax in '%s': %v", rule.Desc, issues.Err()),
+					RuleID:      rule.ID,
+					Description: fmt.Sprintf("Invalid rule syntax in '%s': %v", rule.Desc, compiled.syntaxErr),
 					Expression:  rule.Expr,
+					RuleIndex:   i,
+					Invalid:     true,
 				},
 			)
 			continue
 		}
-
-		program, err := v.env.Program(ast)
-		if err != nil {
+		if compiled.err != nil {
 			result.Errors = append(
 				result.Errors, &models.ValidationError{
-					Description: fmt.Sprintf("Failed to process rule '%s': %v", rule.Desc, err),
+					RuleID:      rule.ID,
+					Description: fmt.Sprintf("Failed to process rule '%s': %v", rule.Desc, compiled.err),
 					Expression:  rule.Expr,
+					RuleIndex:   i,
+					Invalid:     true,
 				},
 			)
 			continue
 		}
+		ast, program := compiled.ast, compiled.program
 
-		out, _, err := program.Eval(
-			map[string]any{
-				"values": values,
-			},
-		)
-
-		validationError := &models.ValidationError{
-			Description: rule.Desc,
-			Expression:  rule.Expr,
-		}
-
-		if err != nil {
-			validationError.Path = extractPath(err.Error())
-			if rule.Severity == WarningSeverity {
-				result.Warnings = append(result.Warnings, validationError)
+		if rule.Match == nil {
+			if validationError := v.evalRule(i, rule, ast, program, values, nil); validationError != nil {
+				addFailure(result, rule.Severity, validationError)
 			} else {
-				result.Errors = append(result.Errors, validationError)
+				addPass(result, i, rule, "")
 			}
 			continue
 		}
 
-		if out.Value() != true {
-			value, path := extractValueFromValues(values, rule.Expr)
-			validationError.Value = value
-			validationError.Path = path
-			if rule.Severity == WarningSeverity {
-				result.Warnings = append(result.Warnings, validationError)
+		matched := false
+		for _, manifest := range manifests {
+			if !rule.Match.Matches(manifest) {
+				continue
+			}
+			matched = true
+			if validationError := v.evalRule(i, rule, ast, program, values, manifest); validationError != nil {
+				validationError.Resource = resourceName(manifest)
+				addFailure(result, rule.Severity, validationError)
 			} else {
-				result.Errors = append(result.Errors, validationError)
+				addPass(result, i, rule, resourceName(manifest))
 			}
 		}
+		if !matched {
+			addSkip(result, i, rule, "no matching manifests")
+		}
 	}
 
 	return result
 }
 
+// compile compiles an expression into a program, once per validator so validating several sets of values against
+// the same rules, e.g. the environments of a matrix, doesn't compile the rules again
+func (v *Validator) compile(expr string, programOpts []cel.ProgramOption) *compiledRule {
+	if compiled, ok := v.programs[expr]; ok {
+		return compiled
+	}
+
+	compiled := &compiledRule{}
+	ast, issues := v.env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		compiled.syntaxErr = issues.Err()
+	} else {
+		compiled.ast = ast
+		compiled.program, compiled.err = v.env.Program(ast, programOpts...)
+	}
+
+	if v.programs == nil {
+		v.programs = make(map[string]*compiledRule)
+	}
+	v.programs[expr] = compiled
+	return compiled
+}
+
+// evalRule evaluates a compiled rule, with object set to the manifest if any, and returns its failure if it did not pass
+func (v *Validator) evalRule(
+	index int,
+	rule models.Rule,
+	ast *cel.Ast,
+	program cel.Program,
+	values map[string]any,
+	manifest map[string]any,
+) *models.ValidationError {
+	vars := map[string]any{
+		"values": values,
+	}
+	if manifest != nil {
+		vars["object"] = manifest
+	}
+
+	out, details, err := program.Eval(vars)
+
+	for _, observer := range v.observers {
+		observer(
+			&Evaluation{
+				Index:   index,
+				Rule:    rule,
+				AST:     ast,
+				Result:  out,
+				Details: details,
+				Err:     err,
+			},
+		)
+	}
+
+	validationError := &models.ValidationError{
+		RuleID:      rule.ID,
+		Description: rule.Desc,
+		Expression:  rule.Expr,
+		RuleIndex:   index,
+	}
+
+	if err != nil {
+		validationError.Path = extractPath(err.Error())
+		return validationError
+	}
+
+	if out.Value() != true {
+		if manifest != nil {
+			validationError.Value, validationError.Path = extractValue(manifest, "object", rule.Expr)
+		} else {
+			validationError.Value, validationError.Path = extractValueFromValues(values, rule.Expr)
+		}
+		return validationError
+	}
+
+	return nil
+}
+
+// addFailure records a failed rule in the result list matching its severity
+func addFailure(result *models.ValidationResult, severity string, validationError *models.ValidationError) {
+	switch severity {
+	case WarningSeverity:
+		result.Warnings = append(result.Warnings, validationError)
+	case InfoSeverity:
+		result.Infos = append(result.Infos, validationError)
+	default:
+		result.Errors = append(result.Errors, validationError)
+	}
+}
+
+// addPass records a rule that passed, on the resource for rules on rendered manifests
+func addPass(result *models.ValidationResult, index int, rule models.Rule, resource string) {
+	result.Passed = append(
+		result.Passed, &models.PassedRule{
+			RuleID:      rule.ID,
+			Description: rule.Desc,
+			Expression:  rule.Expr,
+			Resource:    resource,
+			RuleIndex:   index,
+		},
+	)
+}
+
+// addSkip records a rule that was not evaluated
+func addSkip(result *models.ValidationResult, index int, rule models.Rule, reason string) {
+	result.Skipped = append(
+		result.Skipped, &models.SkippedRule{
+			RuleID:      rule.ID,
+			Description: rule.Desc,
+			Expression:  rule.Expr,
+			Reason:      reason,
+			RuleIndex:   index,
+		},
+	)
+}
+
 // extractPath extracts the path from a CEL error message
 func extractPath(errMsg string) string {
 	patterns := []string{
@@ -209,7 +487,12 @@ func extractPath(errMsg string) string {
 
 // extractValueFromValues extracts the relevant value from the values map based on the CEL expression
 func extractValueFromValues(values map[string]any, expr string) (any, string) {
-	parts := strings.Split(expr, "values.")
+	return extractValue(values, "values", expr)
+}
+
+// extractValue extracts the relevant value from the map bound to the variable based on the CEL expression
+func extractValue(values map[string]any, variable string, expr string) (any, string) {
+	parts := strings.Split(expr, variable+".")
 	if len(parts) < 2 {
 		return nil, ""
 	}
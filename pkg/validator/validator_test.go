@@ -165,6 +165,214 @@ func TestValidator_ValidateChart_InvalidRules(t *testing.T) {
 	assert.Contains(t, err.Error(), "values.cel.yaml")
 }
 
+func TestValidator_ValidateChart_Severities(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, writeFile(t, tempDir, "values.yaml", "replicas: 0"))
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+  - expr: "values.replicas > 1"
+    desc: "replicas should be highly available"
+    severity: warning
+  - expr: "values.replicas > 2"
+    desc: "replicas could be increased"
+    severity: info`,
+		),
+	)
+
+	v := New()
+	res, err := v.ValidateChart(tempDir, []string{"values.yaml"}, []string{"values.cel.yaml"})
+	require.NoError(t, err)
+
+	require.Len(t, res.Errors, 1)
+	require.Len(t, res.Warnings, 1)
+	require.Len(t, res.Infos, 1)
+	assert.Equal(t, "replicas could be increased", res.Infos[0].Description)
+}
+
+func TestValidator_ValidateChart_InvalidSeverity(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, writeFile(t, tempDir, "values.yaml", "replicas: 1"))
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+    severity: critical`,
+		),
+	)
+
+	v := New()
+	_, err := v.ValidateChart(tempDir, []string{"values.yaml"}, []string{"values.cel.yaml"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid severity 'critical' in rule 'replicas must be positive'")
+}
+
+func TestValidator_ValidateChart_Profiles(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.yaml", `
+image:
+  tag: latest
+debug: true`,
+		),
+	)
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - id: image-tag-not-latest
+    expr: 'values.image.tag != "latest"'
+    desc: "image tag should be pinned"
+    severity: warning
+  - id: debug-disabled
+    expr: "!values.debug"
+    desc: "debug must be disabled"
+profiles:
+  dev:
+    rules:
+      debug-disabled:
+        disabled: true
+  prod:
+    rules:
+      image-tag-not-latest:
+        severity: error`,
+		),
+	)
+
+	tests := []struct {
+		name             string
+		profile          string
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			name:             "no profile",
+			expectedErrors:   []string{"debug-disabled"},
+			expectedWarnings: []string{"image-tag-not-latest"},
+		},
+		{
+			name:             "dev profile disables rule",
+			profile:          "dev",
+			expectedErrors:   []string{},
+			expectedWarnings: []string{"image-tag-not-latest"},
+		},
+		{
+			name:             "prod profile raises severity",
+			profile:          "prod",
+			expectedErrors:   []string{"image-tag-not-latest", "debug-disabled"},
+			expectedWarnings: []string{},
+		},
+	}
+
+	ruleIDs := func(errs []*models.ValidationError) []string {
+		ids := make([]string, 0, len(errs))
+		for _, err := range errs {
+			ids = append(ids, err.RuleID)
+		}
+		return ids
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				v := New(WithProfile(tt.profile))
+				res, err := v.ValidateChart(tempDir, []string{"values.yaml"}, []string{"values.cel.yaml"})
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.expectedErrors, ruleIDs(res.Errors))
+				assert.Equal(t, tt.expectedWarnings, ruleIDs(res.Warnings))
+			},
+		)
+	}
+
+	t.Run(
+		"unknown profile", func(t *testing.T) {
+			v := New(WithProfile("staging"))
+			_, err := v.ValidateChart(tempDir, []string{"values.yaml"}, []string{"values.cel.yaml"})
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "profile 'staging' not found (available profiles: [dev prod])")
+		},
+	)
+}
+
+func TestValidator_ValidateChart_ProfileUnknownRule(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, writeFile(t, tempDir, "values.yaml", "replicas: 1"))
+	require.NoError(
+		t, writeFile(
+			t, tempDir, "values.cel.yaml", `
+rules:
+  - id: replicas-positive
+    expr: "values.replicas > 0"
+    desc: "replicas must be positive"
+profiles:
+  prod:
+    rules:
+      replicas-ha:
+        severity: error`,
+		),
+	)
+
+	v := New(WithProfile("prod"))
+	_, err := v.ValidateChart(tempDir, []string{"values.yaml"}, []string{"values.cel.yaml"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "profile 'prod' overrides unknown rule 'replicas-ha'")
+}
+
+func TestValidator_Validate_ReusesPrograms(t *testing.T) {
+	rules := &models.ValidationRules{
+		Rules: []models.Rule{
+			{Expr: "values.replicas > 0", Desc: "replicas must be positive"},
+			{Expr: "values.replicas <", Desc: "invalid"},
+		},
+	}
+
+	v := New()
+	dev, err := v.Validate(map[string]any{"replicas": 1}, rules)
+	require.NoError(t, err)
+	compiled := v.programs["values.replicas > 0"]
+	require.NotNil(t, compiled)
+
+	prod, err := v.Validate(map[string]any{"replicas": 0}, rules)
+	require.NoError(t, err)
+
+	assert.Len(t, v.programs, 2)
+	assert.Same(t, compiled, v.programs["values.replicas > 0"])
+	assert.Len(t, dev.Passed, 1)
+	assert.Len(t, dev.Errors, 1)
+	assert.Empty(t, prod.Passed)
+	require.Len(t, prod.Errors, 2)
+	assert.Contains(t, prod.Errors[1].Description, "Invalid rule syntax in 'invalid'")
+}
+
+func TestValidator_Validate_Summary(t *testing.T) {
+	rules := &models.ValidationRules{
+		Rules: []models.Rule{
+			{Expr: "values.replicas > 1", Desc: "replicas must be highly available"},
+			{Expr: "values.replicas > 1", Desc: "replicas should be highly available", Severity: WarningSeverity},
+			{Expr: "values.replicas > 0", Desc: "replicas must be positive"},
+			{Expr: "values.replicas > 0", Desc: "replicas should be positive", Severity: WarningSeverity},
+		},
+	}
+
+	result, err := New().Validate(map[string]any{"replicas": 1}, rules)
+	require.NoError(t, err)
+
+	// Rules without ID are counted separately even if they share an expression
+	summary := result.Summary()
+	summary.DurationMs = 0
+	assert.Equal(t, models.Summary{Rules: 4, Passed: 2, Failed: 2, Errors: 1, Warnings: 1}, summary)
+}
+
 func TestValidator_ExtractPath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -371,6 +579,27 @@ func TestValidationResult_Error(t *testing.T) {
 			},
 			expected: "Found 1 error(s):\n\n❌ port must be valid\n   Rule: values.service.port <= 65535\n   Path: service.port\n   Current value: 70000\n\nFound 1 warning(s):\n\n⚠️ resources should be specified\n   Rule: has(values.resources)\n   Path: resources\n   Current value: <nil>",
 		},
+		{
+			name: "warnings and infos",
+			result: &models.ValidationResult{
+				Warnings: []*models.ValidationError{
+					{
+						Description: "resources should be specified",
+						Expression:  "has(values.resources)",
+						Path:        "resources",
+					},
+				},
+				Infos: []*models.ValidationError{
+					{
+						Description: "consider enabling autoscaling",
+						Expression:  "values.autoscaling.enabled",
+						Value:       false,
+						Path:        "autoscaling.enabled",
+					},
+				},
+			},
+			expected: "Found 1 warning(s):\n\n⚠️ resources should be specified\n   Rule: has(values.resources)\n   Path: resources\n   Current value: <nil>\n\nFound 1 info(s):\n\nℹ️ consider enabling autoscaling\n   Rule: values.autoscaling.enabled\n   Path: autoscaling.enabled\n   Current value: false",
+		},
 		{
 			name: "only warnings",
 			result: &models.ValidationResult{
@@ -22,7 +22,7 @@ func (l *ValuesLoader) LoadAndMergeValues(valuesFiles []string) (map[string]any,
 		if err != nil {
 			return nil, fmt.Errorf("failed to load values from %s: %v", path, err)
 		}
-		mergedValues = l.mergeValues(mergedValues, values)
+		mergedValues = l.MergeValues(mergedValues, values)
 	}
 
 	return mergedValues, nil
@@ -43,8 +43,8 @@ func (l *ValuesLoader) loadValuesFile(path string) (map[string]any, error) {
 	return values, nil
 }
 
-// mergeValues deeply merges two value maps
-func (l *ValuesLoader) mergeValues(base, overlay map[string]any) map[string]any {
+// MergeValues deeply merges two value maps, with overlay values taking precedence
+func (l *ValuesLoader) MergeValues(base, overlay map[string]any) map[string]any {
 	result := make(map[string]any)
 
 	// Copy base values
@@ -58,7 +58,7 @@ func (l *ValuesLoader) mergeValues(base, overlay map[string]any) map[string]any
 		if baseVal, ok := result[k]; ok {
 			if baseMap, isBaseMap := baseVal.(map[string]any); isBaseMap {
 				if overlayMap, isOverlayMap := v.(map[string]any); isOverlayMap {
-					result[k] = l.mergeValues(baseMap, overlayMap)
+					result[k] = l.MergeValues(baseMap, overlayMap)
 					continue
 				}
 			}